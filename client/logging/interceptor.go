@@ -0,0 +1,174 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jamillosantos/logctx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+
+	"github.com/jamillosantos/go-grpc-interceptors/internal/logfields"
+)
+
+const (
+	fieldGRPCService      = "grpc.service"
+	fieldGRPCMethod       = "grpc.method"
+	fieldGRPCFullMethod   = "grpc.full_method"
+	fieldGRPCTarget       = "grpc.target"
+	fieldGRPCStatus       = "grpc.status"
+	fieldGRPCStatusCode   = "grpc.status_code"
+	fieldGRPCRequest      = "grpc.request"
+	fieldGRPCResponse     = "grpc.response"
+	fieldGRPCMsgsReceived = "grpc.stream.msgs_received"
+	fieldGRPCMsgsSent     = "grpc.stream.msgs_sent"
+)
+
+// The fields of the call when the logctx logger already carries the ones of a server call (see
+// server/logging.WithContextLogger), which happens when the client is used by a handler.
+const (
+	fieldGRPCClientService    = "grpc.client.service"
+	fieldGRPCClientMethod     = "grpc.client.method"
+	fieldGRPCClientFullMethod = "grpc.client.full_method"
+)
+
+const (
+	messageRequest       = "%s started"
+	messageResponse      = "%s completed"
+	messageResponseError = "%s completed with error"
+	messageStreamRecv    = "%s message received"
+	messageStreamSend    = "%s message sent"
+)
+
+type loggingOptions struct {
+	extractRequest       func(ctx context.Context, req interface{}) (context.Context, zapcore.ObjectMarshaler, error)
+	extractResponse      func(ctx context.Context, resp interface{}) zapcore.ObjectMarshaler
+	handleError          func(ctx context.Context, err error) []zap.Field
+	logRequest           bool
+	requestMessage       string
+	logResponse          bool
+	responseMessage      string
+	responseErrorMessage string
+	traceContext         bool
+}
+
+// Option is a function that configures the client logging interceptors.
+type Option func(*loggingOptions)
+
+func defaultOptions() loggingOptions {
+	return loggingOptions{
+		extractRequest:       nil,
+		extractResponse:      nil,
+		handleError:          logfields.HandleError,
+		logRequest:           false,
+		requestMessage:       messageRequest,
+		logResponse:          true,
+		responseMessage:      messageResponse,
+		responseErrorMessage: messageResponseError,
+	}
+}
+
+// UnaryInterceptor logs the outgoing unary calls made through a grpc.ClientConn. The log entries carry the same
+// fields as the ones written by the server/logging package, plus the target of the connection. When the call is made
+// by a handler whose logctx logger already carries the fields of the server call, the service and the method are
+// logged as "grpc.client.service", "grpc.client.method" and "grpc.client.full_method" instead.
+func UnaryInterceptor(options ...Option) grpc.UnaryClientInterceptor {
+	opts := defaultOptions()
+	for _, opt := range options {
+		opt(&opts)
+	}
+	return func(ctx context.Context, fullMethod string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		var reqObj zapcore.ObjectMarshaler
+		if opts.extractRequest != nil {
+			c, reqZapObj, err := opts.extractRequest(ctx, req)
+			if err != nil {
+				return err
+			}
+			reqObj = reqZapObj
+			if c != nil {
+				ctx = c
+			}
+		}
+		service, method := logfields.ExtractServiceAndMethod(fullMethod)
+		commonFields := buildCommonFields(ctx, service, method, fullMethod, cc)
+		if opts.traceContext {
+			ctx = withTraceContext(ctx)
+		}
+
+		logRequest(ctx, method, commonFields, reqObj, opts)
+		err := invoker(ctx, fullMethod, req, reply, cc, callOpts...)
+
+		var respObj zapcore.ObjectMarshaler
+		if opts.extractResponse != nil && err == nil {
+			respObj = opts.extractResponse(ctx, reply)
+		}
+		logResponse(ctx, method, commonFields, reqObj, respObj, err, opts)
+		return err
+	}
+}
+
+func logRequest(ctx context.Context, method string, fields []zap.Field, reqObj zapcore.ObjectMarshaler, opts loggingOptions) {
+	if !opts.logRequest {
+		return
+	}
+
+	if reqObj != nil {
+		fields = append(fields, zap.Object(fieldGRPCRequest, reqObj))
+	}
+
+	logctx.Info(ctx, fmt.Sprintf(opts.requestMessage, method), fields...)
+}
+
+func logResponse(ctx context.Context, method string, fields []zap.Field, reqObj zapcore.ObjectMarshaler, respObj zapcore.ObjectMarshaler, err error, opts loggingOptions, extraFields ...zap.Field) {
+	if !opts.logResponse && err == nil {
+		return
+	}
+
+	stCode := logfields.StatusCode(err)
+	fields = append(
+		fields,
+		zap.String(fieldGRPCStatus, stCode.String()),
+		zap.Uint32(fieldGRPCStatusCode, uint32(stCode)),
+	)
+
+	if !opts.logRequest && reqObj != nil {
+		fields = append(fields, zap.Object(fieldGRPCRequest, reqObj))
+	}
+
+	if respObj != nil {
+		fields = append(fields, zap.Object(fieldGRPCResponse, respObj))
+	}
+
+	fields = append(fields, extraFields...)
+
+	writeLog := logctx.Info
+	logMessage := opts.responseMessage
+
+	if err != nil {
+		writeLog = logctx.Error
+		logMessage = opts.responseErrorMessage
+		if opts.handleError != nil {
+			fields = append(fields, opts.handleError(ctx, err)...)
+		}
+	}
+
+	writeLog(ctx, fmt.Sprintf(logMessage, method), fields...)
+}
+
+func buildCommonFields(ctx context.Context, service string, method string, fullMethod string, cc *grpc.ClientConn) []zap.Field {
+	f := make([]zap.Field, 0, 5)
+	if logfields.HasCallFields(ctx) {
+		f = append(f, zap.String(fieldGRPCClientService, service))
+		f = append(f, zap.String(fieldGRPCClientMethod, method))
+		f = append(f, zap.String(fieldGRPCClientFullMethod, fullMethod))
+	} else {
+		f = append(f, zap.String(fieldGRPCService, service))
+		f = append(f, zap.String(fieldGRPCMethod, method))
+		f = append(f, zap.String(fieldGRPCFullMethod, fullMethod))
+	}
+	if cc != nil {
+		f = append(f, zap.String(fieldGRPCTarget, cc.Target()))
+	}
+	return f
+}
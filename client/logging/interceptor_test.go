@@ -0,0 +1,115 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jamillosantos/logctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	serverlogging "github.com/jamillosantos/go-grpc-interceptors/server/logging"
+)
+
+func TestUnaryInterceptor(t *testing.T) {
+	t.Run("should log the call completion", func(t *testing.T) {
+		ctx, obs := createObserver()
+		err := UnaryInterceptor(WithOperationStarted(true))(ctx, "/pkg.Service/Method", nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return nil
+		})
+		require.NoError(t, err)
+		entries := obs.All()
+		require.Len(t, entries, 2)
+		assert.Equal(t, "Method started", entries[0].Message)
+		assert.Equal(t, "Method completed", entries[1].Message)
+		fields := entries[1].ContextMap()
+		assert.Equal(t, "Service", fields[fieldGRPCService])
+		assert.Equal(t, "Method", fields[fieldGRPCMethod])
+		assert.Equal(t, "/pkg.Service/Method", fields[fieldGRPCFullMethod])
+		assert.Equal(t, "OK", fields[fieldGRPCStatus])
+	})
+
+	t.Run("should log the error details when the call fails", func(t *testing.T) {
+		st, err := status.New(codes.InvalidArgument, "invalid").WithDetails(&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "name", Description: "required"}},
+		})
+		require.NoError(t, err)
+
+		ctx, obs := createObserver()
+		gotErr := UnaryInterceptor(WithOperationCompleted(false))(ctx, "/pkg.Service/Method", nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return st.Err()
+		})
+		require.Error(t, gotErr)
+		entries := obs.All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, "Method completed with error", entries[0].Message)
+		fields := entries[0].ContextMap()
+		assert.Equal(t, "InvalidArgument", fields[fieldGRPCStatus])
+		assert.Equal(t, "invalid", fields["grpc.error.message"])
+		assert.Equal(t, []interface{}{map[string]interface{}{
+			"$type": "BadRequest",
+			"field_violations": []interface{}{
+				map[string]interface{}{"field": "name", "description": "required"},
+			},
+		}}, fields["grpc.error.details"])
+	})
+}
+
+func TestUnaryInterceptor_ServerCall(t *testing.T) {
+	ctx, obs := createObserver()
+	_, err := serverlogging.UnaryInterceptor()(ctx, nil, &grpc.UnaryServerInfo{
+		FullMethod: "/pkg.Service/Method",
+	}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, UnaryInterceptor()(ctx, "/pkg.Other/Call", nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return nil
+		})
+	})
+	require.NoError(t, err)
+
+	entries := obs.FilterMessage("Call completed").All()
+	require.Len(t, entries, 1)
+	assertUniqueKeys(t, entries[0])
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "Service", fields[fieldGRPCService])
+	assert.Equal(t, "/pkg.Service/Method", fields[fieldGRPCFullMethod])
+	assert.Equal(t, "Other", fields[fieldGRPCClientService])
+	assert.Equal(t, "Call", fields[fieldGRPCClientMethod])
+	assert.Equal(t, "/pkg.Other/Call", fields[fieldGRPCClientFullMethod])
+
+	t.Run("should keep the fields of calls made outside of a server call", func(t *testing.T) {
+		ctx, obs := createObserver()
+		err := UnaryInterceptor()(ctx, "/pkg.Other/Call", nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return nil
+		})
+		require.NoError(t, err)
+
+		fields := obs.FilterMessage("Call completed").All()[0].ContextMap()
+		assert.Equal(t, "Other", fields[fieldGRPCService])
+		assert.Equal(t, "Call", fields[fieldGRPCMethod])
+		assert.Equal(t, "/pkg.Other/Call", fields[fieldGRPCFullMethod])
+		assert.NotContains(t, fields, fieldGRPCClientService)
+	})
+}
+
+// assertUniqueKeys asserts that entry does not have repeated keys, which ContextMap would hide.
+func assertUniqueKeys(t *testing.T, entry observer.LoggedEntry) {
+	t.Helper()
+	keys := make(map[string]struct{}, len(entry.Context))
+	for _, f := range entry.Context {
+		_, repeated := keys[f.Key]
+		assert.False(t, repeated, "repeated key %s", f.Key)
+		keys[f.Key] = struct{}{}
+	}
+}
+
+func createObserver() (context.Context, *observer.ObservedLogs) {
+	zc, obs := observer.New(zapcore.DebugLevel)
+	return logctx.WithLogger(context.Background(), zap.New(zc)), obs
+}
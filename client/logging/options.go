@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"context"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// WithOperationStarted enables, or disables, the log entry written before the call is sent.
+func WithOperationStarted(enable bool) Option {
+	return func(opts *loggingOptions) {
+		opts.logRequest = enable
+	}
+}
+
+// WithOperationCompleted enables, or disables, the log entry written when the call succeeds. Failed calls are always
+// logged.
+func WithOperationCompleted(enable bool) Option {
+	return func(opts *loggingOptions) {
+		opts.logResponse = enable
+	}
+}
+
+// WithRequestExtractor sets the function that renders the outgoing request (or each message sent in a stream).
+func WithRequestExtractor(extractor func(ctx context.Context, req interface{}) (context.Context, zapcore.ObjectMarshaler, error)) Option {
+	return func(opts *loggingOptions) {
+		opts.extractRequest = extractor
+	}
+}
+
+// WithResponseExtractor sets the function that renders the response (or each message received in a stream).
+func WithResponseExtractor(extractor func(ctx context.Context, resp interface{}) zapcore.ObjectMarshaler) Option {
+	return func(opts *loggingOptions) {
+		opts.extractResponse = extractor
+	}
+}
+
+// WithErrorHandler replaces the function that renders the error returned by the call.
+func WithErrorHandler(handler func(ctx context.Context, err error) []zapcore.Field) Option {
+	return func(opts *loggingOptions) {
+		opts.handleError = handler
+	}
+}
+
+// WithTraceContext enables, or disables, the W3C Trace Context propagation. A new span is started for every call, as a
+// child of the trace context of the server call being handled (see the server/logging WithTraceContext option) or of
+// the `traceparent` already present in the outgoing metadata, and sent in the `traceparent` and `tracestate` metadata.
+// When there is no trace context, a new trace is started. The trace, span and parent span IDs are added to the entries
+// of the call, except for the calls made by a server call, whose logger already carries its IDs: the span of the call
+// is added as "grpc.client.span_id" instead.
+func WithTraceContext(enable bool) Option {
+	return func(opts *loggingOptions) {
+		opts.traceContext = enable
+	}
+}
@@ -0,0 +1,125 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jamillosantos/logctx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+
+	"github.com/jamillosantos/go-grpc-interceptors/internal/logfields"
+)
+
+// StreamInterceptor is the streaming counterpart of UnaryInterceptor. The stream is considered completed when RecvMsg
+// returns an error (io.EOF meaning success) or, when the server does not stream (eg: client streaming calls finished
+// with CloseAndRecv), when its single response is received. Streams that are not consumed until the end are not logged
+// as completed.
+//
+// When a request (or response) extractor is configured, it is applied to every message sent (or received) through
+// the stream and each message is logged individually.
+func StreamInterceptor(options ...Option) grpc.StreamClientInterceptor {
+	opts := defaultOptions()
+	for _, opt := range options {
+		opt(&opts)
+	}
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, fullMethod string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		service, method := logfields.ExtractServiceAndMethod(fullMethod)
+		commonFields := buildCommonFields(ctx, service, method, fullMethod, cc)
+		if opts.traceContext {
+			ctx = withTraceContext(ctx)
+		}
+
+		logRequest(ctx, method, commonFields, nil, opts)
+		cs, err := streamer(ctx, desc, cc, fullMethod, callOpts...)
+		if err != nil {
+			logResponse(ctx, method, commonFields, nil, nil, err, opts)
+			return nil, err
+		}
+		return &loggingClientStream{
+			ClientStream: cs,
+			ctx:          ctx,
+			desc:         desc,
+			method:       method,
+			commonFields: commonFields,
+			opts:         opts,
+		}, nil
+	}
+}
+
+// loggingClientStream wraps a grpc.ClientStream counting the messages that go through it, applying the configured
+// extractors to each one of them and logging the completion of the stream.
+type loggingClientStream struct {
+	grpc.ClientStream
+	ctx          context.Context
+	desc         *grpc.StreamDesc
+	method       string
+	commonFields []zap.Field
+	opts         loggingOptions
+	received     atomic.Uint64
+	sent         atomic.Uint64
+	completed    sync.Once
+}
+
+func (s *loggingClientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err != nil {
+		return err
+	}
+	s.sent.Add(1)
+
+	if s.opts.extractRequest == nil {
+		return nil
+	}
+	_, reqObj, err := s.opts.extractRequest(s.ctx, m)
+	if err != nil {
+		return err
+	}
+	s.logMessage(messageStreamSend, fieldGRPCRequest, reqObj)
+	return nil
+}
+
+func (s *loggingClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		s.complete(err)
+		return err
+	}
+	s.received.Add(1)
+
+	if s.opts.extractResponse != nil {
+		s.logMessage(messageStreamRecv, fieldGRPCResponse, s.opts.extractResponse(s.ctx, m))
+	}
+	if !s.desc.ServerStreams {
+		// The server sends a single response, so there is nothing else to receive.
+		s.complete(nil)
+	}
+	return nil
+}
+
+func (s *loggingClientStream) complete(err error) {
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	s.completed.Do(func() {
+		logResponse(s.ctx, s.method, s.commonFields, nil, nil, err, s.opts,
+			zap.Uint64(fieldGRPCMsgsReceived, s.received.Load()),
+			zap.Uint64(fieldGRPCMsgsSent, s.sent.Load()),
+		)
+	})
+}
+
+func (s *loggingClientStream) logMessage(message, key string, obj zapcore.ObjectMarshaler) {
+	// SendMsg and RecvMsg can be called from different goroutines, so the common fields are copied instead of appended.
+	fields := make([]zap.Field, len(s.commonFields), len(s.commonFields)+1)
+	copy(fields, s.commonFields)
+	if obj != nil {
+		fields = append(fields, zap.Object(key, obj))
+	}
+	logctx.Info(s.ctx, fmt.Sprintf(message, s.method), fields...)
+}
@@ -0,0 +1,105 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeClientStream struct {
+	grpc.ClientStream
+	recv int
+	err  error
+}
+
+func (f *fakeClientStream) SendMsg(_ interface{}) error {
+	return nil
+}
+
+func (f *fakeClientStream) CloseSend() error {
+	return nil
+}
+
+func (f *fakeClientStream) RecvMsg(_ interface{}) error {
+	if f.recv == 0 {
+		return f.err
+	}
+	f.recv--
+	return nil
+}
+
+func TestStreamInterceptor(t *testing.T) {
+	t.Run("should log the completion when the stream reaches EOF", func(t *testing.T) {
+		ctx, obs := createObserver()
+		cs, err := StreamInterceptor()(ctx, &grpc.StreamDesc{ServerStreams: true}, nil, "/pkg.Service/Method", func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return &fakeClientStream{recv: 2, err: io.EOF}, nil
+		})
+		require.NoError(t, err)
+		require.NoError(t, cs.SendMsg(nil))
+		for cs.RecvMsg(nil) == nil {
+		}
+		assert.ErrorIs(t, cs.RecvMsg(nil), io.EOF)
+
+		entries := obs.All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, "Method completed", entries[0].Message)
+		fields := entries[0].ContextMap()
+		assert.Equal(t, "OK", fields[fieldGRPCStatus])
+		assert.Equal(t, uint64(2), fields[fieldGRPCMsgsReceived])
+		assert.Equal(t, uint64(1), fields[fieldGRPCMsgsSent])
+	})
+
+	t.Run("should log the completion when the single response of a client stream is received", func(t *testing.T) {
+		ctx, obs := createObserver()
+		cs, err := StreamInterceptor()(ctx, &grpc.StreamDesc{ClientStreams: true}, nil, "/pkg.Service/Method", func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return &fakeClientStream{recv: 1, err: io.EOF}, nil
+		})
+		require.NoError(t, err)
+		require.NoError(t, cs.SendMsg(nil))
+		require.NoError(t, cs.SendMsg(nil))
+		require.NoError(t, cs.CloseSend())
+		require.NoError(t, cs.RecvMsg(nil))
+
+		entries := obs.All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, "Method completed", entries[0].Message)
+		fields := entries[0].ContextMap()
+		assert.Equal(t, "OK", fields[fieldGRPCStatus])
+		assert.Equal(t, uint64(1), fields[fieldGRPCMsgsReceived])
+		assert.Equal(t, uint64(2), fields[fieldGRPCMsgsSent])
+	})
+
+	t.Run("should log the error of a client stream", func(t *testing.T) {
+		ctx, obs := createObserver()
+		cs, err := StreamInterceptor()(ctx, &grpc.StreamDesc{ClientStreams: true}, nil, "/pkg.Service/Method", func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return &fakeClientStream{err: status.Error(codes.InvalidArgument, "invalid")}, nil
+		})
+		require.NoError(t, err)
+		require.NoError(t, cs.CloseSend())
+		require.Error(t, cs.RecvMsg(nil))
+
+		entries := obs.All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, "Method completed with error", entries[0].Message)
+		assert.Equal(t, "InvalidArgument", entries[0].ContextMap()[fieldGRPCStatus])
+	})
+
+	t.Run("should log the error when the stream cannot be created", func(t *testing.T) {
+		ctx, obs := createObserver()
+		_, err := StreamInterceptor()(ctx, &grpc.StreamDesc{}, nil, "/pkg.Service/Method", func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return nil, status.Error(codes.Unavailable, "unavailable")
+		})
+		require.Error(t, err)
+		entries := obs.All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, zapcore.ErrorLevel, entries[0].Level)
+		assert.Equal(t, "Unavailable", entries[0].ContextMap()[fieldGRPCStatus])
+	})
+}
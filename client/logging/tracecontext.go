@@ -0,0 +1,33 @@
+package logging
+
+import (
+	"context"
+	"encoding/hex"
+
+	"github.com/jamillosantos/logctx"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/jamillosantos/go-grpc-interceptors/internal/tracecontext"
+)
+
+// fieldGRPCClientSpanID is the span of the call when the trace context came from the server call, whose trace, span and
+// parent span IDs are already carried by the logctx logger (see server/logging.WithTraceContext).
+const fieldGRPCClientSpanID = "grpc.client.span_id"
+
+// withTraceContext starts a new span for the call and adds it to the outgoing metadata and to the logctx logger of
+// the context.
+func withTraceContext(ctx context.Context) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if parent, ok := tracecontext.FromContext(ctx); ok {
+		tc := parent.Child()
+		ctx = metadata.NewOutgoingContext(ctx, tc.AppendToMetadata(md))
+		return logctx.WithFields(ctx, zap.String(fieldGRPCClientSpanID, hex.EncodeToString(tc.SpanID[:])))
+	}
+	tc := tracecontext.New()
+	if parent, ok := tracecontext.FromMetadata(md); ok {
+		tc = parent.Child()
+	}
+	ctx = metadata.NewOutgoingContext(ctx, tc.AppendToMetadata(md))
+	return logctx.WithFields(ctx, tc.Fields()...)
+}
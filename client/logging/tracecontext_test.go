@@ -0,0 +1,112 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jamillosantos/logctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/jamillosantos/go-grpc-interceptors/internal/tracecontext"
+	serverlogging "github.com/jamillosantos/go-grpc-interceptors/server/logging"
+)
+
+func TestUnaryInterceptor_TraceContext(t *testing.T) {
+	parent, ok := tracecontext.Parse("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "congo=t61rcWkgMzE")
+	require.True(t, ok)
+
+	t.Run("should propagate the trace context of the server call", func(t *testing.T) {
+		ctx, obs := createObserver()
+		// The server interceptor adds the trace context of the server call to the context and to its logctx logger.
+		ctx = logctx.WithFields(tracecontext.NewContext(ctx, parent), parent.Fields()...)
+
+		var md metadata.MD
+		err := UnaryInterceptor(WithTraceContext(true))(ctx, "/pkg.Service/Method", nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			md, _ = metadata.FromOutgoingContext(ctx)
+			return nil
+		})
+		require.NoError(t, err)
+
+		sent, ok := tracecontext.FromMetadata(md)
+		require.True(t, ok)
+		assert.Equal(t, parent.TraceID, sent.TraceID)
+		assert.NotEqual(t, parent.SpanID, sent.SpanID)
+		assert.Equal(t, parent.Flags, sent.Flags)
+		assert.Equal(t, parent.State, sent.State)
+
+		entries := obs.All()
+		require.Len(t, entries, 1)
+		assertUniqueKeys(t, entries[0])
+		fields := entries[0].ContextMap()
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", fields[tracecontext.FieldTraceID])
+		assert.Equal(t, "00f067aa0ba902b7", fields[tracecontext.FieldSpanID], "the span of the server call should be kept")
+		assert.Equal(t, sent.Traceparent()[36:52], fields[fieldGRPCClientSpanID])
+	})
+
+	t.Run("should propagate the trace context of the server interceptor", func(t *testing.T) {
+		ctx, obs := createObserver()
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(tracecontext.HeaderTraceparent, parent.Traceparent()))
+
+		var md metadata.MD
+		_, err := serverlogging.UnaryInterceptor(serverlogging.WithTraceContext(true))(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, UnaryInterceptor(WithTraceContext(true))(ctx, "/pkg.Other/Call", nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				md, _ = metadata.FromOutgoingContext(ctx)
+				return nil
+			})
+		})
+		require.NoError(t, err)
+
+		sent, ok := tracecontext.FromMetadata(md)
+		require.True(t, ok)
+		server := obs.FilterMessage("Method completed").All()[0].ContextMap()
+		entries := obs.FilterMessage("Call completed").All()
+		require.Len(t, entries, 1)
+		assertUniqueKeys(t, entries[0])
+		fields := entries[0].ContextMap()
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", fields[tracecontext.FieldTraceID])
+		assert.Equal(t, server[tracecontext.FieldSpanID], fields[tracecontext.FieldSpanID])
+		assert.Equal(t, "00f067aa0ba902b7", fields[tracecontext.FieldParentSpanID])
+		assert.Equal(t, sent.Traceparent()[36:52], fields[fieldGRPCClientSpanID])
+		assert.NotEqual(t, fields[tracecontext.FieldSpanID], fields[fieldGRPCClientSpanID])
+	})
+
+	t.Run("should continue the trace of the outgoing metadata", func(t *testing.T) {
+		ctx, _ := createObserver()
+		ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs(tracecontext.HeaderTraceparent, parent.Traceparent(), "key", "value"))
+
+		var md metadata.MD
+		err := UnaryInterceptor(WithTraceContext(true))(ctx, "/pkg.Service/Method", nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			md, _ = metadata.FromOutgoingContext(ctx)
+			return nil
+		})
+		require.NoError(t, err)
+
+		sent, ok := tracecontext.FromMetadata(md)
+		require.True(t, ok)
+		assert.Equal(t, parent.TraceID, sent.TraceID)
+		assert.NotEqual(t, parent.SpanID, sent.SpanID)
+		assert.Equal(t, []string{"value"}, md.Get("key"))
+	})
+
+	t.Run("should start a new trace", func(t *testing.T) {
+		ctx, obs := createObserver()
+
+		var md metadata.MD
+		err := UnaryInterceptor(WithTraceContext(true))(ctx, "/pkg.Service/Method", nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			md, _ = metadata.FromOutgoingContext(ctx)
+			return nil
+		})
+		require.NoError(t, err)
+
+		_, ok := tracecontext.FromMetadata(md)
+		assert.True(t, ok)
+		fields := obs.All()[0].ContextMap()
+		assert.Contains(t, fields, tracecontext.FieldTraceID)
+		assert.NotContains(t, fields, tracecontext.FieldParentSpanID)
+	})
+}
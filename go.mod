@@ -7,7 +7,9 @@ require (
 	github.com/jamillosantos/logctx v0.2.0
 	github.com/stretchr/testify v1.7.1
 	go.uber.org/zap v1.21.0
+	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013
 	google.golang.org/grpc v1.50.1
+	google.golang.org/protobuf v1.30.0
 )
 
 require (
@@ -23,7 +25,5 @@ require (
 	golang.org/x/text v0.3.7 // indirect
 	golang.org/x/tools v0.1.11-0.20220316014157-77aa08bb151a // indirect
 	golang.org/x/xerrors v0.0.0-20220411194840-2f41105eb62f // indirect
-	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013 // indirect
-	google.golang.org/protobuf v1.28.0 // indirect
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
 )
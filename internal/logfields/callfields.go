@@ -0,0 +1,17 @@
+package logfields
+
+import "context"
+
+type callFieldsKey struct{}
+
+// WithCallFields returns a copy of ctx marked as having a logctx logger that already carries the service and method
+// fields of a server call, so the client calls made with it can avoid logging the same keys twice.
+func WithCallFields(ctx context.Context) context.Context {
+	return context.WithValue(ctx, callFieldsKey{}, true)
+}
+
+// HasCallFields reports whether the logctx logger of ctx carries the fields of a server call (see WithCallFields).
+func HasCallFields(ctx context.Context) bool {
+	has, _ := ctx.Value(callFieldsKey{}).(bool)
+	return has
+}
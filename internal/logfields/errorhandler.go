@@ -0,0 +1,387 @@
+// Package logfields contains the zap fields and marshalers that are shared between the server and client logging
+// interceptors.
+package logfields
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	FieldGRPCErrorMessage = "grpc.error.message"
+	FieldGRPCErrorDetails = "grpc.error.details"
+	FieldGRPCErrorKind    = "grpc.error.kind"
+)
+
+// The kinds of error, as classified by ClassifyError.
+const (
+	ErrorKindCanceled         = "canceled"
+	ErrorKindDeadlineExceeded = "deadline_exceeded"
+	ErrorKindStatus           = "status"
+	ErrorKindWrappedStatus    = "wrapped_status"
+	ErrorKindPlain            = "plain"
+)
+
+// StatusFromError returns the gRPC status carried by err or by any error it wraps (found with errors.As), unlike
+// status.FromError, and false when there is none.
+func StatusFromError(err error) (*status.Status, bool) {
+	var withStatus interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &withStatus) {
+		return withStatus.GRPCStatus(), true
+	}
+	return nil, false
+}
+
+// StatusCode returns the code of the gRPC status sent to the client for err, computed as grpc.Server does: the code of
+// the status of err, when it has one, or the code of the context error it wraps. Statuses wrapped by err are not taken
+// into account, as gRPC sends them as Unknown. It is OK for nil errors.
+func StatusCode(err error) codes.Code {
+	if st, ok := status.FromError(err); ok {
+		return st.Code()
+	}
+	return status.FromContextError(err).Code()
+}
+
+// RedactedError is an error whose gRPC status details were redacted before being logged. It has the message of the
+// original error, which it wraps, and is classified as it (see ClassifyError).
+type RedactedError struct {
+	Err    error
+	Status *status.Status
+}
+
+func (e *RedactedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RedactedError) Unwrap() error {
+	return e.Err
+}
+
+func (e *RedactedError) GRPCStatus() *status.Status {
+	return e.Status
+}
+
+// ClassifyError tells whether err is caused by the cancellation of the call by the client, by its deadline being
+// exceeded, is a gRPC status, wraps a gRPC status (found with errors.As) or is a plain Go error.
+func ClassifyError(ctx context.Context, err error) string {
+	var redacted *RedactedError
+	if errors.As(err, &redacted) {
+		err = redacted.Err
+	}
+	code := codes.Unknown
+	if st, ok := StatusFromError(err); ok {
+		code = st.Code()
+	}
+	switch {
+	case errors.Is(err, context.Canceled) || code == codes.Canceled || errors.Is(ctx.Err(), context.Canceled):
+		return ErrorKindCanceled
+	case errors.Is(err, context.DeadlineExceeded) || code == codes.DeadlineExceeded || errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return ErrorKindDeadlineExceeded
+	}
+	if _, ok := err.(interface{ GRPCStatus() *status.Status }); ok {
+		return ErrorKindStatus
+	}
+	if _, ok := StatusFromError(err); ok {
+		return ErrorKindWrappedStatus
+	}
+	return ErrorKindPlain
+}
+
+// DetailMarshaler renders a status detail as a zap object.
+type DetailMarshaler func(detail proto.Message) zapcore.ObjectMarshaler
+
+// ErrorHandler renders errors and, when they carry a gRPC status, their message and details. Details are rendered, in
+// order of precedence, by the marshaler registered for their message name, by ErrDetailObjectMarshaler for the well
+// known errdetails messages or, for any other message, through protojson.
+type ErrorHandler struct {
+	resolver   DetailResolver
+	marshalers map[protoreflect.FullName]DetailMarshaler
+}
+
+// DetailResolver finds the message types of the status details. *protoregistry.Types implements it.
+type DetailResolver interface {
+	protoregistry.MessageTypeResolver
+	protoregistry.ExtensionTypeResolver
+}
+
+// ErrorHandlerOption configures an ErrorHandler.
+type ErrorHandlerOption func(*ErrorHandler)
+
+// NewErrorHandler creates an ErrorHandler. By default, the details are resolved through protoregistry.GlobalTypes.
+func NewErrorHandler(options ...ErrorHandlerOption) *ErrorHandler {
+	h := &ErrorHandler{
+		resolver:   protoregistry.GlobalTypes,
+		marshalers: make(map[protoreflect.FullName]DetailMarshaler),
+	}
+	for _, opt := range options {
+		opt(h)
+	}
+	return h
+}
+
+// WithDetailResolver sets the resolver used to find the message types of the details (and of the google.protobuf.Any
+// fields inside them). The types it does not know are still resolved through protoregistry.GlobalTypes.
+func WithDetailResolver(resolver DetailResolver) ErrorHandlerOption {
+	return func(h *ErrorHandler) {
+		h.resolver = globalFallbackResolver{resolver}
+	}
+}
+
+// globalFallbackResolver resolves, through protoregistry.GlobalTypes, the types its resolver does not know.
+type globalFallbackResolver struct {
+	resolver DetailResolver
+}
+
+func (r globalFallbackResolver) FindMessageByName(name protoreflect.FullName) (protoreflect.MessageType, error) {
+	if mt, err := r.resolver.FindMessageByName(name); err == nil {
+		return mt, nil
+	}
+	return protoregistry.GlobalTypes.FindMessageByName(name)
+}
+
+func (r globalFallbackResolver) FindMessageByURL(url string) (protoreflect.MessageType, error) {
+	if mt, err := r.resolver.FindMessageByURL(url); err == nil {
+		return mt, nil
+	}
+	return protoregistry.GlobalTypes.FindMessageByURL(url)
+}
+
+func (r globalFallbackResolver) FindExtensionByName(field protoreflect.FullName) (protoreflect.ExtensionType, error) {
+	if xt, err := r.resolver.FindExtensionByName(field); err == nil {
+		return xt, nil
+	}
+	return protoregistry.GlobalTypes.FindExtensionByName(field)
+}
+
+func (r globalFallbackResolver) FindExtensionByNumber(message protoreflect.FullName, field protoreflect.FieldNumber) (protoreflect.ExtensionType, error) {
+	if xt, err := r.resolver.FindExtensionByNumber(message, field); err == nil {
+		return xt, nil
+	}
+	return protoregistry.GlobalTypes.FindExtensionByNumber(message, field)
+}
+
+// WithDetailMarshaler renders the details of the given message type with marshaler.
+func WithDetailMarshaler(name protoreflect.FullName, marshaler DetailMarshaler) ErrorHandlerOption {
+	return func(h *ErrorHandler) {
+		h.marshalers[name] = marshaler
+	}
+}
+
+var defaultErrorHandler = NewErrorHandler()
+
+// HandleError is the default error handler of the logging interceptors. It adds the error itself, its kind (see
+// ClassifyError) and, when the error carries a gRPC status, its message and details.
+func HandleError(ctx context.Context, err error) []zap.Field {
+	return defaultErrorHandler.HandleError(ctx, err)
+}
+
+// HandleError adds the error itself, its kind (see ClassifyError) and, when the error carries (or wraps) a gRPC status,
+// its message and details.
+func (h *ErrorHandler) HandleError(ctx context.Context, err error) []zap.Field {
+	if err == nil {
+		return nil
+	}
+	fields := []zap.Field{zap.Error(err), zap.String(FieldGRPCErrorKind, ClassifyError(ctx, err))}
+	if st, ok := StatusFromError(err); ok {
+		fields = append(fields, zap.String(FieldGRPCErrorMessage, st.Message()))
+		details := st.Proto().GetDetails()
+		if len(details) > 0 {
+			fields = append(fields, zap.Array(FieldGRPCErrorDetails, &errorDetailsObjectMarshaler{h, details}))
+		}
+	}
+	return fields
+}
+
+type errorDetailsObjectMarshaler struct {
+	handler *ErrorHandler
+	details []*anypb.Any
+}
+
+func (e errorDetailsObjectMarshaler) MarshalLogArray(encoder zapcore.ArrayEncoder) error {
+	for _, detail := range e.details {
+		msg, err := anypb.UnmarshalNew(detail, proto.UnmarshalOptions{Resolver: e.handler.resolver})
+		if err != nil {
+			if err := encoder.AppendObject(unresolvedDetailObjectMarshaler{detail}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := encoder.AppendObject(e.handler.detailMarshaler(msg)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *ErrorHandler) detailMarshaler(detail proto.Message) zapcore.ObjectMarshaler {
+	if marshaler, ok := h.marshalers[proto.MessageName(detail)]; ok {
+		return marshaler(detail)
+	}
+	switch detail.(type) {
+	case *errdetails.BadRequest, *errdetails.QuotaFailure, *errdetails.RequestInfo, *errdetails.ResourceInfo, *errdetails.DebugInfo, *errdetails.Help, *errdetails.LocalizedMessage, *errdetails.PreconditionFailure, *errdetails.RetryInfo, *errdetails.ErrorInfo:
+		return &ErrDetailObjectMarshaler{detail}
+	default:
+		return &protoJSONObjectMarshaler{detail, h.resolver}
+	}
+}
+
+// unresolvedDetailObjectMarshaler renders the details whose message type is unknown as their full message name, taken
+// from the type URL, and raw value.
+type unresolvedDetailObjectMarshaler struct {
+	detail *anypb.Any
+}
+
+func (u unresolvedDetailObjectMarshaler) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	encoder.AddString("$type", string(u.detail.MessageName()))
+	encoder.AddBinary("value", u.detail.GetValue())
+	return nil
+}
+
+// ErrDetailObjectMarshaler renders the well known errdetails messages as zap objects.
+type ErrDetailObjectMarshaler struct {
+	detail proto.Message
+}
+
+func (e ErrDetailObjectMarshaler) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	encoder.AddString("$type", string(proto.MessageName(e.detail).Name()))
+	switch v := e.detail.(type) {
+	case *errdetails.BadRequest:
+		encoder.AddArray("field_violations", &fieldViolationsObjectMarshaler{v.FieldViolations})
+	case *errdetails.QuotaFailure:
+		encoder.AddArray("violations", &quotaViolationsObjectMarshaler{v.Violations})
+	case *errdetails.RequestInfo:
+		encoder.AddString("request_id", v.RequestId)
+		encoder.AddString("serving_data", v.ServingData)
+	case *errdetails.ResourceInfo:
+		encoder.AddString("resource_type", v.ResourceType)
+		encoder.AddString("resource_name", v.ResourceName)
+		encoder.AddString("owner", v.Owner)
+		encoder.AddString("description", v.Description)
+	case *errdetails.DebugInfo:
+		encoder.AddArray("stack_entries", &stringsArrayMarshaler{v.StackEntries})
+		encoder.AddString("detail", v.Detail)
+	case *errdetails.Help:
+		encoder.AddArray("links", &linksObjectMarshaler{v.Links})
+	case *errdetails.LocalizedMessage:
+		encoder.AddString("locale", v.Locale)
+		encoder.AddString("message", v.Message)
+	case *errdetails.PreconditionFailure:
+		encoder.AddArray("violations", &preconditionViolationsObjectMarshaler{v.Violations})
+	case *errdetails.RetryInfo:
+		encoder.AddString("retry_delay", v.RetryDelay.String())
+	case *errdetails.ErrorInfo:
+		encoder.AddString("reason", v.Reason)
+		encoder.AddString("domain", v.Domain)
+		for k, v := range v.Metadata {
+			encoder.AddString(fmt.Sprintf("metadata.%s", k), v)
+		}
+	}
+	return nil
+}
+
+type quotaViolationsObjectMarshaler struct {
+	violations []*errdetails.QuotaFailure_Violation
+}
+
+func (q quotaViolationsObjectMarshaler) MarshalLogArray(encoder zapcore.ArrayEncoder) error {
+	for _, violation := range q.violations {
+		encoder.AppendObject(&quotaViolationObjectMarshaler{violation})
+	}
+	return nil
+}
+
+type quotaViolationObjectMarshaler struct {
+	violation *errdetails.QuotaFailure_Violation
+}
+
+func (q quotaViolationObjectMarshaler) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	encoder.AddString("subject", q.violation.Subject)
+	encoder.AddString("description", q.violation.Description)
+	return nil
+}
+
+type fieldViolationsObjectMarshaler struct {
+	fieldViolations []*errdetails.BadRequest_FieldViolation
+}
+
+func (f fieldViolationsObjectMarshaler) MarshalLogArray(encoder zapcore.ArrayEncoder) error {
+	for _, fieldViolation := range f.fieldViolations {
+		encoder.AppendObject(&fieldViolationObjectMarshaler{fieldViolation})
+	}
+	return nil
+}
+
+type fieldViolationObjectMarshaler struct {
+	fieldViolation *errdetails.BadRequest_FieldViolation
+}
+
+func (f fieldViolationObjectMarshaler) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	encoder.AddString("field", f.fieldViolation.Field)
+	encoder.AddString("description", f.fieldViolation.Description)
+	return nil
+}
+
+type stringsArrayMarshaler struct {
+	arr []string
+}
+
+func (s stringsArrayMarshaler) MarshalLogArray(encoder zapcore.ArrayEncoder) error {
+	for _, str := range s.arr {
+		encoder.AppendString(str)
+	}
+	return nil
+}
+
+type linksObjectMarshaler struct {
+	links []*errdetails.Help_Link
+}
+
+func (l linksObjectMarshaler) MarshalLogArray(encoder zapcore.ArrayEncoder) error {
+	for _, link := range l.links {
+		encoder.AppendObject(&linkObjectMarshaler{link})
+	}
+	return nil
+}
+
+type linkObjectMarshaler struct {
+	link *errdetails.Help_Link
+}
+
+func (l linkObjectMarshaler) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	encoder.AddString("description", l.link.Description)
+	encoder.AddString("url", l.link.Url)
+	return nil
+}
+
+type preconditionViolationsObjectMarshaler struct {
+	violations []*errdetails.PreconditionFailure_Violation
+}
+
+func (p preconditionViolationsObjectMarshaler) MarshalLogArray(encoder zapcore.ArrayEncoder) error {
+	for _, violation := range p.violations {
+		encoder.AppendObject(&preconditionViolationObjectMarshaler{violation})
+	}
+	return nil
+}
+
+type preconditionViolationObjectMarshaler struct {
+	violation *errdetails.PreconditionFailure_Violation
+}
+
+func (p preconditionViolationObjectMarshaler) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	encoder.AddString("type", p.violation.Type)
+	encoder.AddString("subject", p.violation.Subject)
+	encoder.AddString("description", p.violation.Description)
+	return nil
+}
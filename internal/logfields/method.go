@@ -0,0 +1,12 @@
+package logfields
+
+import "strings"
+
+// ExtractServiceAndMethod splits a gRPC full method ("/package.Service/Method") into its service and method names.
+func ExtractServiceAndMethod(fullMethod string) (string, string) {
+	toks := strings.Split(fullMethod[strings.LastIndexByte(fullMethod, '.')+1:], "/")
+	if len(toks) == 2 {
+		return toks[0], toks[1]
+	}
+	return "", ""
+}
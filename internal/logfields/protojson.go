@@ -0,0 +1,107 @@
+package logfields
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// protoJSONObjectMarshaler renders any proto message as a zap object, with the same structure of its protojson
+// encoding (using the proto field names) and its full message name under "$type".
+type protoJSONObjectMarshaler struct {
+	msg      proto.Message
+	resolver DetailResolver
+}
+
+func (p protoJSONObjectMarshaler) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	encoder.AddString("$type", string(proto.MessageName(p.msg)))
+	data, err := protojson.MarshalOptions{UseProtoNames: true, Resolver: p.resolver}.Marshal(p.msg)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	// Some well known types (eg: google.protobuf.Timestamp) are not encoded as JSON objects.
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		fields = map[string]interface{}{"value": value}
+	}
+	return jsonObjectMarshaler(fields).MarshalLogObject(encoder)
+}
+
+type jsonObjectMarshaler map[string]interface{}
+
+func (j jsonObjectMarshaler) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	keys := make([]string, 0, len(j))
+	for k := range j {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var err error
+		switch v := j[k].(type) {
+		case map[string]interface{}:
+			err = encoder.AddObject(k, jsonObjectMarshaler(v))
+		case []interface{}:
+			err = encoder.AddArray(k, jsonArrayMarshaler(v))
+		case string:
+			encoder.AddString(k, v)
+		case bool:
+			encoder.AddBool(k, v)
+		case json.Number:
+			if i, err := v.Int64(); err == nil {
+				encoder.AddInt64(k, i)
+			} else if f, err := v.Float64(); err == nil {
+				encoder.AddFloat64(k, f)
+			} else {
+				encoder.AddString(k, v.String())
+			}
+		default:
+			err = encoder.AddReflected(k, v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type jsonArrayMarshaler []interface{}
+
+func (j jsonArrayMarshaler) MarshalLogArray(encoder zapcore.ArrayEncoder) error {
+	for _, value := range j {
+		var err error
+		switch v := value.(type) {
+		case map[string]interface{}:
+			err = encoder.AppendObject(jsonObjectMarshaler(v))
+		case []interface{}:
+			err = encoder.AppendArray(jsonArrayMarshaler(v))
+		case string:
+			encoder.AppendString(v)
+		case bool:
+			encoder.AppendBool(v)
+		case json.Number:
+			if i, err := v.Int64(); err == nil {
+				encoder.AppendInt64(i)
+			} else if f, err := v.Float64(); err == nil {
+				encoder.AppendFloat64(f)
+			} else {
+				encoder.AppendString(v.String())
+			}
+		default:
+			err = encoder.AppendReflected(v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+//go:build go1.21
+
+// Package slogcore implements a zapcore.Core that writes to a log/slog handler, so the entries of the logging
+// interceptors, built with zap fields and zapcore.ObjectMarshaler, can be emitted as slog records. Objects are turned
+// into groups and zap namespaces into handler groups.
+package slogcore
+
+import (
+	"context"
+	"log/slog"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	keyLogger     = "logger"
+	keyStacktrace = "stacktrace"
+)
+
+type core struct {
+	handler slog.Handler
+}
+
+// New creates a zapcore.Core that writes its entries to handler.
+func New(handler slog.Handler) zapcore.Core {
+	return &core{handler: handler}
+}
+
+func (c *core) Enabled(level zapcore.Level) bool {
+	return c.handler.Enabled(context.Background(), Level(level))
+}
+
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	enc := newAttrEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	handler := c.handler
+	if len(enc.stack[0]) > 0 {
+		handler = handler.WithAttrs(enc.stack[0])
+	}
+	for i, group := range enc.groups {
+		handler = handler.WithGroup(group)
+		if len(enc.stack[i+1]) > 0 {
+			handler = handler.WithAttrs(enc.stack[i+1])
+		}
+	}
+	return &core{handler: handler}
+}
+
+func (c *core) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+func (c *core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	r := slog.NewRecord(entry.Time, Level(entry.Level), entry.Message, 0)
+	if entry.LoggerName != "" {
+		r.AddAttrs(slog.String(keyLogger, entry.LoggerName))
+	}
+	enc := newAttrEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	r.AddAttrs(enc.attrs()...)
+	if entry.Stack != "" {
+		r.AddAttrs(slog.String(keyStacktrace, entry.Stack))
+	}
+	return c.handler.Handle(context.Background(), r)
+}
+
+func (c *core) Sync() error {
+	return nil
+}
+
+// Level converts a zap level into its slog counterpart. The levels above zapcore.ErrorLevel are mapped above
+// slog.LevelError, keeping their order.
+func Level(level zapcore.Level) slog.Level {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return slog.LevelDebug
+	case level == zapcore.InfoLevel:
+		return slog.LevelInfo
+	case level == zapcore.WarnLevel:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError + slog.Level(level-zapcore.ErrorLevel)
+	}
+}
@@ -0,0 +1,90 @@
+//go:build go1.21
+
+package slogcore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func newTestLogger(t *testing.T, level slog.Level) (*zap.Logger, func() map[string]interface{}) {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := zap.New(New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: level})))
+	return logger, func() map[string]interface{} {
+		t.Helper()
+		if buf.Len() == 0 {
+			return nil
+		}
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		buf.Reset()
+		return record
+	}
+}
+
+func TestCore(t *testing.T) {
+	t.Run("should convert fields into attributes and groups", func(t *testing.T) {
+		logger, record := newTestLogger(t, slog.LevelDebug)
+		logger.With(zap.String("service", "Service")).Warn("message",
+			zap.Int("count", 2),
+			zap.Duration("duration", time.Second),
+			zap.Error(errors.New("failure")),
+			zap.Object("request", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+				enc.AddString("name", "john")
+				return enc.AddArray("tags", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+					arr.AppendString("a")
+					return arr.AppendObject(zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+						enc.AddBool("b", true)
+						return nil
+					}))
+				}))
+			})),
+		)
+		got := record()
+		assert.Equal(t, "WARN", got["level"])
+		assert.Equal(t, "message", got["msg"])
+		assert.Equal(t, "Service", got["service"])
+		assert.Equal(t, float64(2), got["count"])
+		assert.Equal(t, float64(time.Second), got["duration"])
+		assert.Equal(t, "failure", got["error"])
+		assert.Equal(t, map[string]interface{}{
+			"name": "john",
+			"tags": []interface{}{"a", map[string]interface{}{"b": true}},
+		}, got["request"])
+	})
+
+	t.Run("should turn namespaces into groups", func(t *testing.T) {
+		logger, record := newTestLogger(t, slog.LevelDebug)
+		logger.With(zap.Namespace("grpc"), zap.String("service", "Service")).Info("message", zap.Namespace("inner"), zap.String("key", "value"))
+		assert.Equal(t, map[string]interface{}{
+			"service": "Service",
+			"inner":   map[string]interface{}{"key": "value"},
+		}, record()["grpc"])
+	})
+
+	t.Run("should respect the level of the handler", func(t *testing.T) {
+		logger, record := newTestLogger(t, slog.LevelInfo)
+		logger.Debug("message")
+		assert.Nil(t, record())
+		logger.Error("message")
+		assert.Equal(t, "ERROR", record()["level"])
+	})
+}
+
+func TestLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelDebug, Level(zapcore.DebugLevel))
+	assert.Equal(t, slog.LevelInfo, Level(zapcore.InfoLevel))
+	assert.Equal(t, slog.LevelWarn, Level(zapcore.WarnLevel))
+	assert.Equal(t, slog.LevelError, Level(zapcore.ErrorLevel))
+	assert.Greater(t, Level(zapcore.FatalLevel), Level(zapcore.PanicLevel))
+}
@@ -0,0 +1,191 @@
+//go:build go1.21
+
+package slogcore
+
+import (
+	"log/slog"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// attrEncoder is a zapcore.ObjectEncoder that builds slog attributes. Every namespace opened pushes a new level into
+// the stack, which is folded into groups by attrs.
+type attrEncoder struct {
+	groups []string
+	stack  [][]slog.Attr
+}
+
+func newAttrEncoder() *attrEncoder {
+	return &attrEncoder{stack: make([][]slog.Attr, 1)}
+}
+
+// attrs returns the attributes added to the encoder, with the namespaces turned into groups.
+func (e *attrEncoder) attrs() []slog.Attr {
+	for i := len(e.groups) - 1; i >= 0; i-- {
+		if len(e.stack[i+1]) == 0 {
+			continue
+		}
+		e.stack[i] = append(e.stack[i], slog.Attr{Key: e.groups[i], Value: slog.GroupValue(e.stack[i+1]...)})
+	}
+	return e.stack[0]
+}
+
+func (e *attrEncoder) add(attr slog.Attr) {
+	top := len(e.stack) - 1
+	e.stack[top] = append(e.stack[top], attr)
+}
+
+func (e *attrEncoder) AddArray(key string, marshaler zapcore.ArrayMarshaler) error {
+	arr := &arrayEncoder{}
+	err := marshaler.MarshalLogArray(arr)
+	e.add(slog.Any(key, arr.values))
+	return err
+}
+
+func (e *attrEncoder) AddObject(key string, marshaler zapcore.ObjectMarshaler) error {
+	obj := newAttrEncoder()
+	err := marshaler.MarshalLogObject(obj)
+	e.add(slog.Attr{Key: key, Value: slog.GroupValue(obj.attrs()...)})
+	return err
+}
+
+func (e *attrEncoder) AddBinary(key string, value []byte) {
+	e.add(slog.Any(key, value))
+}
+
+func (e *attrEncoder) AddByteString(key string, value []byte) {
+	e.add(slog.String(key, string(value)))
+}
+
+func (e *attrEncoder) AddBool(key string, value bool) {
+	e.add(slog.Bool(key, value))
+}
+
+func (e *attrEncoder) AddComplex128(key string, value complex128) {
+	e.add(slog.Any(key, value))
+}
+
+func (e *attrEncoder) AddComplex64(key string, value complex64) {
+	e.add(slog.Any(key, value))
+}
+
+func (e *attrEncoder) AddDuration(key string, value time.Duration) {
+	e.add(slog.Duration(key, value))
+}
+
+func (e *attrEncoder) AddFloat64(key string, value float64) {
+	e.add(slog.Float64(key, value))
+}
+
+func (e *attrEncoder) AddFloat32(key string, value float32) {
+	e.add(slog.Float64(key, float64(value)))
+}
+
+func (e *attrEncoder) AddInt(key string, value int) {
+	e.add(slog.Int(key, value))
+}
+
+func (e *attrEncoder) AddInt64(key string, value int64) {
+	e.add(slog.Int64(key, value))
+}
+
+func (e *attrEncoder) AddInt32(key string, value int32) {
+	e.add(slog.Int64(key, int64(value)))
+}
+
+func (e *attrEncoder) AddInt16(key string, value int16) {
+	e.add(slog.Int64(key, int64(value)))
+}
+
+func (e *attrEncoder) AddInt8(key string, value int8) {
+	e.add(slog.Int64(key, int64(value)))
+}
+
+func (e *attrEncoder) AddString(key, value string) {
+	e.add(slog.String(key, value))
+}
+
+func (e *attrEncoder) AddTime(key string, value time.Time) {
+	e.add(slog.Time(key, value))
+}
+
+func (e *attrEncoder) AddUint(key string, value uint) {
+	e.add(slog.Uint64(key, uint64(value)))
+}
+
+func (e *attrEncoder) AddUint64(key string, value uint64) {
+	e.add(slog.Uint64(key, value))
+}
+
+func (e *attrEncoder) AddUint32(key string, value uint32) {
+	e.add(slog.Uint64(key, uint64(value)))
+}
+
+func (e *attrEncoder) AddUint16(key string, value uint16) {
+	e.add(slog.Uint64(key, uint64(value)))
+}
+
+func (e *attrEncoder) AddUint8(key string, value uint8) {
+	e.add(slog.Uint64(key, uint64(value)))
+}
+
+func (e *attrEncoder) AddUintptr(key string, value uintptr) {
+	e.add(slog.Uint64(key, uint64(value)))
+}
+
+func (e *attrEncoder) AddReflected(key string, value interface{}) error {
+	e.add(slog.Any(key, value))
+	return nil
+}
+
+func (e *attrEncoder) OpenNamespace(key string) {
+	e.groups = append(e.groups, key)
+	e.stack = append(e.stack, nil)
+}
+
+// arrayEncoder is a zapcore.ArrayEncoder that collects plain Go values, as slog has no list kind. Objects are
+// collected as maps.
+type arrayEncoder struct {
+	values []interface{}
+}
+
+func (a *arrayEncoder) AppendArray(marshaler zapcore.ArrayMarshaler) error {
+	arr := &arrayEncoder{}
+	err := marshaler.MarshalLogArray(arr)
+	a.values = append(a.values, arr.values)
+	return err
+}
+
+func (a *arrayEncoder) AppendObject(marshaler zapcore.ObjectMarshaler) error {
+	obj := zapcore.NewMapObjectEncoder()
+	err := marshaler.MarshalLogObject(obj)
+	a.values = append(a.values, obj.Fields)
+	return err
+}
+
+func (a *arrayEncoder) AppendReflected(value interface{}) error {
+	a.values = append(a.values, value)
+	return nil
+}
+
+func (a *arrayEncoder) AppendBool(value bool)              { a.values = append(a.values, value) }
+func (a *arrayEncoder) AppendByteString(value []byte)      { a.values = append(a.values, string(value)) }
+func (a *arrayEncoder) AppendComplex128(value complex128)  { a.values = append(a.values, value) }
+func (a *arrayEncoder) AppendComplex64(value complex64)    { a.values = append(a.values, value) }
+func (a *arrayEncoder) AppendDuration(value time.Duration) { a.values = append(a.values, value) }
+func (a *arrayEncoder) AppendFloat64(value float64)        { a.values = append(a.values, value) }
+func (a *arrayEncoder) AppendFloat32(value float32)        { a.values = append(a.values, value) }
+func (a *arrayEncoder) AppendInt(value int)                { a.values = append(a.values, value) }
+func (a *arrayEncoder) AppendInt64(value int64)            { a.values = append(a.values, value) }
+func (a *arrayEncoder) AppendInt32(value int32)            { a.values = append(a.values, value) }
+func (a *arrayEncoder) AppendInt16(value int16)            { a.values = append(a.values, value) }
+func (a *arrayEncoder) AppendInt8(value int8)              { a.values = append(a.values, value) }
+func (a *arrayEncoder) AppendString(value string)          { a.values = append(a.values, value) }
+func (a *arrayEncoder) AppendTime(value time.Time)         { a.values = append(a.values, value) }
+func (a *arrayEncoder) AppendUint(value uint)              { a.values = append(a.values, value) }
+func (a *arrayEncoder) AppendUint64(value uint64)          { a.values = append(a.values, value) }
+func (a *arrayEncoder) AppendUint32(value uint32)          { a.values = append(a.values, value) }
+func (a *arrayEncoder) AppendUint16(value uint16)          { a.values = append(a.values, value) }
+func (a *arrayEncoder) AppendUint8(value uint8)            { a.values = append(a.values, value) }
+func (a *arrayEncoder) AppendUintptr(value uintptr)        { a.values = append(a.values, value) }
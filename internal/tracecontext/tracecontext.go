@@ -0,0 +1,178 @@
+// Package tracecontext implements the parsing and propagation of the W3C Trace Context
+// (https://www.w3.org/TR/trace-context/) `traceparent` and `tracestate` metadata headers, so the log entries of the
+// server and client logging interceptors can be correlated even without a tracing backend.
+package tracecontext
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	HeaderTraceparent = "traceparent"
+	HeaderTracestate  = "tracestate"
+
+	FieldTraceID      = "trace_id"
+	FieldSpanID       = "span_id"
+	FieldParentSpanID = "parent_span_id"
+
+	// maxTracestateLength is the maximum length of a tracestate header that is propagated, as recommended by the spec.
+	maxTracestateLength = 512
+)
+
+// TraceContext is the trace context of a single RPC.
+type TraceContext struct {
+	TraceID      [16]byte
+	SpanID       [8]byte
+	ParentSpanID [8]byte
+	Flags        byte
+	State        string
+}
+
+type ctxKey struct{}
+
+// NewContext returns a copy of ctx carrying tc.
+func NewContext(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, ctxKey{}, tc)
+}
+
+// FromContext returns the trace context carried by ctx.
+func FromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(ctxKey{}).(TraceContext)
+	return tc, ok
+}
+
+// Parse parses a traceparent header and its accompanying tracestate. It returns false when traceparent is malformed,
+// in which case tracestate must be ignored too.
+func Parse(traceparent, tracestate string) (TraceContext, bool) {
+	var tc TraceContext
+	// version "-" trace-id "-" parent-id "-" trace-flags
+	if len(traceparent) < 55 || traceparent[2] != '-' || traceparent[35] != '-' || traceparent[52] != '-' {
+		return tc, false
+	}
+	version, ok := decodeHex(traceparent[0:2], 1)
+	if !ok || version[0] == 0xff {
+		return tc, false
+	}
+	// Version 00 has a fixed length, future versions may append fields separated by "-".
+	if (version[0] == 0 && len(traceparent) != 55) || (len(traceparent) > 55 && traceparent[55] != '-') {
+		return tc, false
+	}
+	traceID, ok := decodeHex(traceparent[3:35], 16)
+	if !ok || isZero(traceID) {
+		return tc, false
+	}
+	spanID, ok := decodeHex(traceparent[36:52], 8)
+	if !ok || isZero(spanID) {
+		return tc, false
+	}
+	flags, ok := decodeHex(traceparent[53:55], 1)
+	if !ok {
+		return tc, false
+	}
+	copy(tc.TraceID[:], traceID)
+	copy(tc.SpanID[:], spanID)
+	tc.Flags = flags[0]
+	if len(tracestate) <= maxTracestateLength {
+		tc.State = strings.TrimSpace(tracestate)
+	}
+	return tc, true
+}
+
+// FromMetadata parses the trace context found in md.
+func FromMetadata(md metadata.MD) (TraceContext, bool) {
+	traceparent := md.Get(HeaderTraceparent)
+	if len(traceparent) != 1 {
+		return TraceContext{}, false
+	}
+	return Parse(traceparent[0], strings.Join(md.Get(HeaderTracestate), ","))
+}
+
+// New starts a new trace.
+func New() TraceContext {
+	var tc TraceContext
+	randomNonZero(tc.TraceID[:])
+	randomNonZero(tc.SpanID[:])
+	return tc
+}
+
+// Child returns the trace context of a new span whose parent is tc.
+func (tc TraceContext) Child() TraceContext {
+	child := tc
+	child.ParentSpanID = tc.SpanID
+	randomNonZero(child.SpanID[:])
+	return child
+}
+
+// Traceparent renders the traceparent header of tc.
+func (tc TraceContext) Traceparent() string {
+	var buf [55]byte
+	buf[0], buf[1], buf[2] = '0', '0', '-'
+	hex.Encode(buf[3:35], tc.TraceID[:])
+	buf[35] = '-'
+	hex.Encode(buf[36:52], tc.SpanID[:])
+	buf[52] = '-'
+	hex.Encode(buf[53:55], []byte{tc.Flags})
+	return string(buf[:])
+}
+
+// AppendToMetadata returns a copy of md with the traceparent and tracestate headers of tc, replacing any previous
+// values.
+func (tc TraceContext) AppendToMetadata(md metadata.MD) metadata.MD {
+	md = md.Copy()
+	md.Set(HeaderTraceparent, tc.Traceparent())
+	md.Delete(HeaderTracestate)
+	if tc.State != "" {
+		md.Set(HeaderTracestate, tc.State)
+	}
+	return md
+}
+
+// Fields returns the trace, span and, when there is one, parent span IDs of tc.
+func (tc TraceContext) Fields() []zap.Field {
+	fields := make([]zap.Field, 0, 3)
+	fields = append(
+		fields,
+		zap.String(FieldTraceID, hex.EncodeToString(tc.TraceID[:])),
+		zap.String(FieldSpanID, hex.EncodeToString(tc.SpanID[:])),
+	)
+	if !isZero(tc.ParentSpanID[:]) {
+		fields = append(fields, zap.String(FieldParentSpanID, hex.EncodeToString(tc.ParentSpanID[:])))
+	}
+	return fields
+}
+
+// decodeHex decodes s, which must be made of n lowercase hex encoded bytes.
+func decodeHex(s string, n int) ([]byte, bool) {
+	if len(s) != 2*n || strings.ToLower(s) != s {
+		return nil, false
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, false
+	}
+	return b, true
+}
+
+func isZero(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func randomNonZero(b []byte) {
+	for {
+		_, _ = rand.Read(b)
+		if !isZero(b) {
+			return
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package tracecontext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc/metadata"
+)
+
+const validTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParse(t *testing.T) {
+	t.Run("should parse a valid traceparent", func(t *testing.T) {
+		tc, ok := Parse(validTraceparent, "congo=t61rcWkgMzE")
+		require.True(t, ok)
+		assert.Equal(t, validTraceparent, tc.Traceparent())
+		assert.Equal(t, byte(1), tc.Flags)
+		assert.Equal(t, "congo=t61rcWkgMzE", tc.State)
+	})
+
+	t.Run("should accept future versions with extra fields", func(t *testing.T) {
+		_, ok := Parse("01"+validTraceparent[2:]+"-extra", "")
+		assert.True(t, ok)
+	})
+
+	t.Run("should reject malformed traceparents", func(t *testing.T) {
+		for _, traceparent := range []string{
+			"",
+			"ff" + validTraceparent[2:],
+			validTraceparent + "-extra",
+			"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+			"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e473g-00f067aa0ba902b7-01",
+			"00_4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		} {
+			_, ok := Parse(traceparent, "")
+			assert.False(t, ok, traceparent)
+		}
+	})
+}
+
+func TestTraceContext_Child(t *testing.T) {
+	tc, ok := Parse(validTraceparent, "")
+	require.True(t, ok)
+
+	child := tc.Child()
+	assert.Equal(t, tc.TraceID, child.TraceID)
+	assert.Equal(t, tc.SpanID, child.ParentSpanID)
+	assert.NotEqual(t, tc.SpanID, child.SpanID)
+	assert.Equal(t, tc.Flags, child.Flags)
+}
+
+func TestTraceContext_AppendToMetadata(t *testing.T) {
+	tc, ok := Parse(validTraceparent, "congo=t61rcWkgMzE")
+	require.True(t, ok)
+
+	md := tc.AppendToMetadata(metadata.Pairs(HeaderTraceparent, "old", "key", "value"))
+	assert.Equal(t, []string{validTraceparent}, md.Get(HeaderTraceparent))
+	assert.Equal(t, []string{"congo=t61rcWkgMzE"}, md.Get(HeaderTracestate))
+	assert.Equal(t, []string{"value"}, md.Get("key"))
+
+	parsed, ok := FromMetadata(md)
+	require.True(t, ok)
+	assert.Equal(t, tc, parsed)
+}
+
+func TestTraceContext_Fields(t *testing.T) {
+	tc := New()
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range tc.Child().Fields() {
+		f.AddTo(enc)
+	}
+	assert.Len(t, enc.Fields[FieldTraceID], 32)
+	assert.Len(t, enc.Fields[FieldSpanID], 16)
+	assert.Len(t, enc.Fields[FieldParentSpanID], 16)
+}
@@ -0,0 +1,52 @@
+package logging
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+type contextAddFieldUpdater string
+
+func (s contextAddFieldUpdater) String() string {
+	return string(s) + "contextAddFieldUpdater"
+}
+
+const ctxKeyAddFields = contextAddFieldUpdater("logging.")
+
+// fieldCollector holds the fields added by the handler, through AddFields, to the completed entry of the call. Once
+// they are read, the collector is closed and the fields added afterwards are ignored.
+type fieldCollector struct {
+	mu     sync.Mutex
+	fields []zap.Field
+	closed bool
+}
+
+func (c *fieldCollector) add(fields []zap.Field) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	c.fields = append(c.fields, fields...)
+}
+
+func (c *fieldCollector) get() []zap.Field {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return c.fields[:len(c.fields):len(c.fields)]
+}
+
+// AddFields adds fields to the entry written when the call completes (eg: the authenticated user, the tenant or the
+// outcome of the operation). It can be called concurrently, from any goroutine of the handler, until the handler
+// returns. Fields added once the completed entry is written, or with a context that does not come from the logging
+// interceptors, are ignored.
+func AddFields(ctx context.Context, fields ...zap.Field) {
+	c, ok := ctx.Value(ctxKeyAddFields).(*fieldCollector)
+	if !ok {
+		return
+	}
+	c.add(fields)
+}
@@ -0,0 +1,78 @@
+package logging
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+func TestAddFields(t *testing.T) {
+	t.Run("should add the fields to the completed entry", func(t *testing.T) {
+		ctx, obs := createObserver()
+		_, _ = UnaryInterceptor(WithOperationStarted(true))(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			var wg sync.WaitGroup
+			for _, key := range []string{"user_id", "tenant"} {
+				wg.Add(1)
+				go func(key string) {
+					defer wg.Done()
+					AddFields(ctx, zap.String(key, key+"-value"))
+				}(key)
+			}
+			wg.Wait()
+			return nil, nil
+		})
+		entries := obs.All()
+		require.Len(t, entries, 2)
+		assert.NotContains(t, entries[0].ContextMap(), "user_id")
+		fields := entries[1].ContextMap()
+		assert.Equal(t, "user_id-value", fields["user_id"])
+		assert.Equal(t, "tenant-value", fields["tenant"])
+	})
+
+	t.Run("should add the fields to the completed entry of streams", func(t *testing.T) {
+		ctx, obs := createObserver()
+		_ = StreamInterceptor()(nil, &fakeServerStream{ctx: ctx}, &grpc.StreamServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(srv interface{}, stream grpc.ServerStream) error {
+			AddFields(stream.Context(), zap.Int("items", 3))
+			return nil
+		})
+		entries := obs.All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, int64(3), entries[0].ContextMap()["items"])
+	})
+
+	t.Run("should ignore the fields added after the call completed", func(t *testing.T) {
+		ctx, obs := createObserver()
+		var handlerCtx context.Context
+		_, _ = UnaryInterceptor()(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			handlerCtx = ctx
+			AddFields(ctx, zap.String("user_id", "user"))
+			return nil, nil
+		})
+		AddFields(handlerCtx, zap.String("late", "value"))
+
+		entries := obs.All()
+		require.Len(t, entries, 1)
+		fields := entries[0].ContextMap()
+		assert.Equal(t, "user", fields["user_id"])
+		assert.NotContains(t, fields, "late")
+		c := handlerCtx.Value(ctxKeyAddFields).(*fieldCollector)
+		assert.Len(t, c.get(), 1, "the late fields should not be kept")
+	})
+
+	t.Run("should ignore contexts without the interceptor", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			AddFields(context.Background(), zap.String("key", "value"))
+		})
+	})
+}
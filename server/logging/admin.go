@@ -0,0 +1,251 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// AdminServiceName is the name of the admin service registered by RegisterAdminService.
+const AdminServiceName = "logging.v1.LoggingAdmin"
+
+const (
+	adminKeyMethods    = "methods"
+	adminKeyFullMethod = "full_method"
+	adminKeyLevel      = "level"
+	adminKeyPayloads   = "payloads"
+	adminKeySampleRate = "sample_rate"
+)
+
+// adminService is the admin service. As it has no generated code, its messages are the well known Struct and Empty:
+//
+//   - ListMethods(Empty) returns {"methods": [{"full_method": ..., "level": ..., "payloads": ..., "sample_rate": ...}]}
+//     with the methods registered on the server and the ones that have a configuration.
+//   - SetMethodConfig({"full_method": ..., "level": ..., "payloads": ..., "sample_rate": ...}) changes the
+//     configuration of a method and returns it. Absent keys are kept, null values are reset.
+//   - ResetMethodConfig({"full_method": ...}) removes the configuration of a method.
+type adminService interface {
+	ListMethods(ctx context.Context, req *emptypb.Empty) (*structpb.Struct, error)
+	SetMethodConfig(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	ResetMethodConfig(ctx context.Context, req *structpb.Struct) (*emptypb.Empty, error)
+}
+
+// serviceInfoProvider is implemented by *grpc.Server.
+type serviceInfoProvider interface {
+	GetServiceInfo() map[string]grpc.ServiceInfo
+}
+
+// RegisterAdminService registers, on s, the admin service that lists the methods of the server and changes their
+// configuration in config (see MethodConfig) at runtime. The service does not authorize its callers, which should be
+// done by the interceptors of the server.
+func RegisterAdminService(s grpc.ServiceRegistrar, config *RuntimeConfig) {
+	srv := &adminServer{config: config}
+	if provider, ok := s.(serviceInfoProvider); ok {
+		srv.services = provider
+	}
+	s.RegisterService(&adminServiceDesc, srv)
+}
+
+type adminServer struct {
+	config   *RuntimeConfig
+	services serviceInfoProvider
+}
+
+func (s *adminServer) ListMethods(_ context.Context, _ *emptypb.Empty) (*structpb.Struct, error) {
+	names := make(map[string]struct{})
+	for name := range s.serverMethods() {
+		names[name] = struct{}{}
+	}
+	for _, name := range s.config.Methods() {
+		names[name] = struct{}{}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	methods := make([]*structpb.Value, 0, len(sorted))
+	for _, name := range sorted {
+		methods = append(methods, structpb.NewStructValue(methodConfigStruct(name, s.config.Method(name))))
+	}
+	return &structpb.Struct{Fields: map[string]*structpb.Value{
+		adminKeyMethods: structpb.NewListValue(&structpb.ListValue{Values: methods}),
+	}}, nil
+}
+
+func (s *adminServer) SetMethodConfig(_ context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	fullMethod, err := s.fullMethod(req)
+	if err != nil {
+		return nil, err
+	}
+	var updates []func(*MethodConfig)
+	for key, value := range req.GetFields() {
+		update, err := methodConfigUpdate(key, value)
+		if err != nil {
+			return nil, err
+		}
+		if update != nil {
+			updates = append(updates, update)
+		}
+	}
+
+	var config MethodConfig
+	s.config.update(func(methods map[string]MethodConfig) {
+		config = methods[fullMethod]
+		for _, update := range updates {
+			update(&config)
+		}
+		methods[fullMethod] = config
+	})
+	return methodConfigStruct(fullMethod, config), nil
+}
+
+func (s *adminServer) ResetMethodConfig(_ context.Context, req *structpb.Struct) (*emptypb.Empty, error) {
+	fullMethod, err := s.fullMethod(req)
+	if err != nil {
+		return nil, err
+	}
+	s.config.ResetMethod(fullMethod)
+	return &emptypb.Empty{}, nil
+}
+
+// serverMethods returns the full methods registered on the server, or nil when they are not known.
+func (s *adminServer) serverMethods() map[string]struct{} {
+	if s.services == nil {
+		return nil
+	}
+	methods := make(map[string]struct{})
+	for service, info := range s.services.GetServiceInfo() {
+		for _, method := range info.Methods {
+			methods["/"+service+"/"+method.Name] = struct{}{}
+		}
+	}
+	return methods
+}
+
+// fullMethod returns the method of req, checking it is registered on the server when the methods are known.
+func (s *adminServer) fullMethod(req *structpb.Struct) (string, error) {
+	value, ok := req.GetFields()[adminKeyFullMethod]
+	if !ok || value.GetStringValue() == "" {
+		return "", status.Errorf(codes.InvalidArgument, "%s is required", adminKeyFullMethod)
+	}
+	fullMethod := value.GetStringValue()
+	if methods := s.serverMethods(); methods != nil {
+		if _, ok := methods[fullMethod]; !ok {
+			return "", status.Errorf(codes.NotFound, "method %s is not registered", fullMethod)
+		}
+	}
+	return fullMethod, nil
+}
+
+// methodConfigUpdate parses a key of a SetMethodConfig request into the change it makes to the configuration.
+func methodConfigUpdate(key string, value *structpb.Value) (func(*MethodConfig), error) {
+	_, null := value.GetKind().(*structpb.Value_NullValue)
+	switch key {
+	case adminKeyFullMethod:
+		return nil, nil
+	case adminKeyLevel:
+		if null {
+			return func(c *MethodConfig) { c.Level = nil }, nil
+		}
+		if _, ok := value.GetKind().(*structpb.Value_StringValue); !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "%s must be a string", key)
+		}
+		var level zapcore.Level
+		if err := level.UnmarshalText([]byte(value.GetStringValue())); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid %s: %s", key, err)
+		}
+		return func(c *MethodConfig) { c.Level = &level }, nil
+	case adminKeyPayloads:
+		if null {
+			return func(c *MethodConfig) { c.Payloads = nil }, nil
+		}
+		if _, ok := value.GetKind().(*structpb.Value_BoolValue); !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "%s must be a bool", key)
+		}
+		payloads := value.GetBoolValue()
+		return func(c *MethodConfig) { c.Payloads = &payloads }, nil
+	case adminKeySampleRate:
+		if null {
+			return func(c *MethodConfig) { c.SampleRate = nil }, nil
+		}
+		rate := value.GetNumberValue()
+		if _, ok := value.GetKind().(*structpb.Value_NumberValue); !ok || rate < 0 || rate > 1 {
+			return nil, status.Errorf(codes.InvalidArgument, "%s must be a number between 0 and 1", key)
+		}
+		return func(c *MethodConfig) { c.SampleRate = &rate }, nil
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown key %s", key)
+	}
+}
+
+func methodConfigStruct(fullMethod string, config MethodConfig) *structpb.Struct {
+	fields := map[string]*structpb.Value{
+		adminKeyFullMethod: structpb.NewStringValue(fullMethod),
+	}
+	if config.Level != nil {
+		fields[adminKeyLevel] = structpb.NewStringValue(config.Level.String())
+	}
+	if config.Payloads != nil {
+		fields[adminKeyPayloads] = structpb.NewBoolValue(*config.Payloads)
+	}
+	if config.SampleRate != nil {
+		fields[adminKeySampleRate] = structpb.NewNumberValue(*config.SampleRate)
+	}
+	return &structpb.Struct{Fields: fields}
+}
+
+var adminServiceDesc = grpc.ServiceDesc{
+	ServiceName: AdminServiceName,
+	HandlerType: (*adminService)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListMethods",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				return adminHandle(srv, ctx, dec, interceptor, "ListMethods", new(emptypb.Empty), func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(adminService).ListMethods(ctx, req.(*emptypb.Empty))
+				})
+			},
+		},
+		{
+			MethodName: "SetMethodConfig",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				return adminHandle(srv, ctx, dec, interceptor, "SetMethodConfig", new(structpb.Struct), func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(adminService).SetMethodConfig(ctx, req.(*structpb.Struct))
+				})
+			},
+		},
+		{
+			MethodName: "ResetMethodConfig",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				return adminHandle(srv, ctx, dec, interceptor, "ResetMethodConfig", new(structpb.Struct), func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(adminService).ResetMethodConfig(ctx, req.(*structpb.Struct))
+				})
+			},
+		},
+	},
+}
+
+// adminHandle decodes the request into in and calls handle through the interceptor of the server, as the generated
+// method handlers do.
+func adminHandle(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor, method string, in interface{}, handle grpc.UnaryHandler) (interface{}, error) {
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return handle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: fmt.Sprintf("/%s/%s", AdminServiceName, method),
+	}
+	return interceptor(ctx, in, info, handle)
+}
@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestRegisterAdminService(t *testing.T) {
+	config := NewRuntimeConfig()
+	server := grpc.NewServer()
+	RegisterAdminService(server, config)
+	lis := bufconn.Listen(1024 * 1024)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	defer server.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx := context.Background()
+	listMethod := "/" + AdminServiceName + "/ListMethods"
+	setMethod := "/" + AdminServiceName + "/SetMethodConfig"
+	resetMethod := "/" + AdminServiceName + "/ResetMethodConfig"
+	newStruct := func(fields map[string]interface{}) *structpb.Struct {
+		s, err := structpb.NewStruct(fields)
+		require.NoError(t, err)
+		return s
+	}
+
+	t.Run("should list the methods of the server", func(t *testing.T) {
+		var resp structpb.Struct
+		require.NoError(t, conn.Invoke(ctx, listMethod, &emptypb.Empty{}, &resp))
+		assert.Equal(t, map[string]interface{}{
+			"methods": []interface{}{
+				map[string]interface{}{"full_method": listMethod},
+				map[string]interface{}{"full_method": resetMethod},
+				map[string]interface{}{"full_method": setMethod},
+			},
+		}, resp.AsMap())
+	})
+
+	t.Run("should change the configuration of a method", func(t *testing.T) {
+		var resp structpb.Struct
+		require.NoError(t, conn.Invoke(ctx, setMethod, newStruct(map[string]interface{}{
+			"full_method": listMethod,
+			"level":       "debug",
+			"sample_rate": 0.5,
+		}), &resp))
+		assert.Equal(t, map[string]interface{}{"full_method": listMethod, "level": "debug", "sample_rate": 0.5}, resp.AsMap())
+
+		require.NoError(t, conn.Invoke(ctx, setMethod, newStruct(map[string]interface{}{
+			"full_method": listMethod,
+			"payloads":    true,
+			"sample_rate": nil,
+		}), &resp))
+		assert.Equal(t, map[string]interface{}{"full_method": listMethod, "level": "debug", "payloads": true}, resp.AsMap(),
+			"absent keys should be kept and null ones reset")
+
+		methodConfig := config.Method(listMethod)
+		require.NotNil(t, methodConfig.Level)
+		assert.Equal(t, zapcore.DebugLevel, *methodConfig.Level)
+		require.NotNil(t, methodConfig.Payloads)
+		assert.True(t, *methodConfig.Payloads)
+		assert.Nil(t, methodConfig.SampleRate)
+
+		require.NoError(t, conn.Invoke(ctx, listMethod, &emptypb.Empty{}, &resp))
+		assert.Contains(t, resp.AsMap()["methods"], map[string]interface{}{"full_method": listMethod, "level": "debug", "payloads": true})
+
+		require.NoError(t, conn.Invoke(ctx, resetMethod, newStruct(map[string]interface{}{"full_method": listMethod}), &emptypb.Empty{}))
+		assert.Empty(t, config.Methods())
+	})
+
+	t.Run("should reject invalid requests", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			req  map[string]interface{}
+			code codes.Code
+		}{
+			"missing method":      {map[string]interface{}{"level": "debug"}, codes.InvalidArgument},
+			"unknown method":      {map[string]interface{}{"full_method": "/pkg.Service/Method"}, codes.NotFound},
+			"invalid level":       {map[string]interface{}{"full_method": listMethod, "level": "verbose"}, codes.InvalidArgument},
+			"invalid payloads":    {map[string]interface{}{"full_method": listMethod, "payloads": "yes"}, codes.InvalidArgument},
+			"invalid sample rate": {map[string]interface{}{"full_method": listMethod, "sample_rate": 2}, codes.InvalidArgument},
+			"unknown key":         {map[string]interface{}{"full_method": listMethod, "sampling": 1}, codes.InvalidArgument},
+		} {
+			t.Run(name, func(t *testing.T) {
+				err := conn.Invoke(ctx, setMethod, newStruct(tc.req), &structpb.Struct{})
+				assert.Equal(t, tc.code, status.Code(err))
+			})
+		}
+		assert.Empty(t, config.Methods())
+	})
+}
@@ -0,0 +1,157 @@
+package logging
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	fieldGRPCDebugFlushed = "grpc.debug_buffer.flushed"
+	fieldGRPCDebugDropped = "grpc.debug_buffer.dropped"
+
+	defaultDebugBufferSize = 100
+)
+
+type debugBufferOptions struct {
+	size             int
+	latencyThreshold time.Duration
+}
+
+// DebugBufferOption configures WithDebugBuffering.
+type DebugBufferOption func(*debugBufferOptions)
+
+// DebugBufferSize sets the maximum number of entries buffered per call. When it is exceeded, the oldest entries are
+// dropped. The default, also used when size is not positive, is 100.
+func DebugBufferSize(size int) DebugBufferOption {
+	return func(opts *debugBufferOptions) {
+		opts.size = size
+	}
+}
+
+// DebugBufferLatencyThreshold also flushes the buffered entries of the calls that take longer than threshold.
+func DebugBufferLatencyThreshold(threshold time.Duration) DebugBufferOption {
+	return func(opts *debugBufferOptions) {
+		opts.latencyThreshold = threshold
+	}
+}
+
+type bufferedEntry struct {
+	core   zapcore.Core
+	entry  zapcore.Entry
+	fields []zapcore.Field
+}
+
+// debugBufferState is what is done with the entries that reach the buffer.
+type debugBufferState int
+
+const (
+	debugBufferBuffering debugBufferState = iota
+	debugBufferPassthrough
+	debugBufferDiscarding
+)
+
+// debugBuffer holds the entries of a call that are below the level of the logger.
+type debugBuffer struct {
+	mu      sync.Mutex
+	size    int
+	state   debugBufferState
+	entries []bufferedEntry
+	dropped int
+}
+
+func newDebugBuffer(size int) *debugBuffer {
+	return &debugBuffer{size: size}
+}
+
+func (b *debugBuffer) add(e bufferedEntry) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case debugBufferPassthrough:
+		return e.core.Write(e.entry, e.fields)
+	case debugBufferDiscarding:
+		return nil
+	}
+	if len(b.entries) >= b.size {
+		b.entries = b.entries[1:]
+		b.dropped++
+	}
+	b.entries = append(b.entries, e)
+	return nil
+}
+
+// flush writes the buffered entries and returns the fields telling how many entries were written and dropped. The
+// entries added afterwards are written right away.
+func (b *debugBuffer) flush() []zap.Field {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = debugBufferPassthrough
+	for _, e := range b.entries {
+		_ = e.core.Write(e.entry, e.fields)
+	}
+	fields := []zap.Field{zap.Int(fieldGRPCDebugFlushed, len(b.entries))}
+	if b.dropped > 0 {
+		fields = append(fields, zap.Int(fieldGRPCDebugDropped, b.dropped))
+	}
+	b.entries = nil
+	return fields
+}
+
+// discard drops the buffered entries and the ones added afterwards.
+func (b *debugBuffer) discard() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = debugBufferDiscarding
+	b.entries = nil
+}
+
+// bufferingCore wraps the core of the logger of a call, sending the debug entries it would not write to the buffer.
+type bufferingCore struct {
+	zapcore.Core
+	buffer *debugBuffer
+}
+
+func (c *bufferingCore) Enabled(level zapcore.Level) bool {
+	return level == zapcore.DebugLevel || c.Core.Enabled(level)
+}
+
+func (c *bufferingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &bufferingCore{Core: c.Core.With(fields), buffer: c.buffer}
+}
+
+func (c *bufferingCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Core.Enabled(entry.Level) {
+		return c.Core.Check(entry, ce)
+	}
+	if entry.Level == zapcore.DebugLevel {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+func (c *bufferingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.buffer.add(bufferedEntry{core: c.Core, entry: entry, fields: append([]zapcore.Field(nil), fields...)})
+}
+
+// bufferingLogger returns logger with its debug entries sent to buffer.
+func bufferingLogger(logger *zap.Logger, buffer *debugBuffer) *zap.Logger {
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &bufferingCore{Core: core, buffer: buffer}
+	}))
+}
+
+// completeDebugBuffer flushes the buffer when the call failed or took longer than the latency threshold, adding the
+// flush fields to the completed entry, and discards it otherwise.
+func completeDebugBuffer(call *callInfo, err error, opts *debugBufferOptions) {
+	if call.debugBuffer == nil {
+		return
+	}
+	if err != nil || (opts.latencyThreshold > 0 && time.Since(call.start) >= opts.latencyThreshold) {
+		call.fields.add(call.debugBuffer.flush())
+		return
+	}
+	call.debugBuffer.discard()
+}
@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jamillosantos/logctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWithDebugBuffering(t *testing.T) {
+	call := func(interceptor grpc.UnaryServerInterceptor, debugEntries int, delay time.Duration, err error) *observer.ObservedLogs {
+		return callMethod(context.Background(), zapcore.InfoLevel, interceptor, nil, func(ctx context.Context, req interface{}) (interface{}, error) {
+			for i := 0; i < debugEntries; i++ {
+				logctx.From(ctx).Debug("debug entry", zap.Int("i", i))
+			}
+			logctx.From(ctx).Info("info entry")
+			time.Sleep(delay)
+			return nil, err
+		})
+	}
+
+	t.Run("should flush the debug entries when the call fails", func(t *testing.T) {
+		obs := call(UnaryInterceptor(WithDebugBuffering()), 2, 0, status.Error(codes.Internal, "failed"))
+
+		entries := obs.All()
+		require.Len(t, entries, 4)
+		assert.Equal(t, "info entry", entries[0].Message, "entries above the level should be written right away")
+		assert.Equal(t, "debug entry", entries[1].Message)
+		assert.Equal(t, zapcore.DebugLevel, entries[1].Level)
+		assert.Equal(t, int64(0), entries[1].ContextMap()["i"])
+		assert.Equal(t, "debug entry", entries[2].Message)
+		assert.Equal(t, "Method completed with error", entries[3].Message)
+		fields := entries[3].ContextMap()
+		assert.Equal(t, int64(2), fields[fieldGRPCDebugFlushed])
+		assert.NotContains(t, fields, fieldGRPCDebugDropped)
+	})
+
+	t.Run("should discard the debug entries when the call succeeds", func(t *testing.T) {
+		obs := call(UnaryInterceptor(WithDebugBuffering()), 2, 0, nil)
+
+		assert.Zero(t, obs.FilterMessage("debug entry").Len())
+		completed := obs.FilterMessage("Method completed").All()
+		require.Len(t, completed, 1)
+		assert.NotContains(t, completed[0].ContextMap(), fieldGRPCDebugFlushed)
+	})
+
+	t.Run("should flush the debug entries when the call is slower than the threshold", func(t *testing.T) {
+		obs := call(UnaryInterceptor(WithDebugBuffering(DebugBufferLatencyThreshold(10*time.Millisecond))), 1, 20*time.Millisecond, nil)
+
+		assert.Equal(t, 1, obs.FilterMessage("debug entry").Len())
+	})
+
+	t.Run("should keep only the newest entries", func(t *testing.T) {
+		obs := call(UnaryInterceptor(WithDebugBuffering(DebugBufferSize(2))), 5, 0, status.Error(codes.Internal, "failed"))
+
+		debug := obs.FilterMessage("debug entry").All()
+		require.Len(t, debug, 2)
+		assert.Equal(t, int64(3), debug[0].ContextMap()["i"])
+		assert.Equal(t, int64(4), debug[1].ContextMap()["i"])
+		fields := obs.FilterMessage("Method completed with error").All()[0].ContextMap()
+		assert.Equal(t, int64(2), fields[fieldGRPCDebugFlushed])
+		assert.Equal(t, int64(3), fields[fieldGRPCDebugDropped])
+	})
+
+	t.Run("should use the default size when the size is not positive", func(t *testing.T) {
+		for _, size := range []int{0, -1} {
+			obs := call(UnaryInterceptor(WithDebugBuffering(DebugBufferSize(size))), 2, 0, status.Error(codes.Internal, "failed"))
+
+			assert.Equal(t, 2, obs.FilterMessage("debug entry").Len())
+		}
+	})
+
+	t.Run("should write the debug entries right away when the logger is enabled for them", func(t *testing.T) {
+		obs := callMethod(context.Background(), zapcore.DebugLevel, UnaryInterceptor(WithDebugBuffering()), nil, func(ctx context.Context, req interface{}) (interface{}, error) {
+			logctx.From(ctx).Debug("debug entry")
+			return nil, nil
+		})
+
+		assert.Equal(t, 1, obs.FilterMessage("debug entry").Len())
+	})
+}
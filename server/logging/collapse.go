@@ -0,0 +1,146 @@
+package logging
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+
+	"github.com/jamillosantos/go-grpc-interceptors/internal/logfields"
+)
+
+const (
+	fieldGRPCErrorFingerprint = "grpc.error.fingerprint"
+	fieldGRPCErrorSuppressed  = "grpc.error.suppressed"
+	fieldGRPCErrorWindow      = "grpc.error.window"
+
+	messageErrorsSuppressed = "%s repeated errors suppressed"
+)
+
+// Fingerprint identifies the errors that are considered repeated by WithErrorCollapsing.
+type Fingerprint func(fullMethod string, err error) string
+
+// DefaultFingerprint identifies the errors by the method, the status code and the ErrorInfo reason and domain of the
+// status or, when there is no ErrorInfo, the status message.
+func DefaultFingerprint(fullMethod string, err error) string {
+	st, ok := logfields.StatusFromError(err)
+	if !ok {
+		st = status.Convert(err)
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return fmt.Sprintf("%s|%s|%s|%s", fullMethod, st.Code(), info.GetDomain(), info.GetReason())
+		}
+	}
+	return fmt.Sprintf("%s|%s|%s", fullMethod, st.Code(), st.Message())
+}
+
+type collapseOptions struct {
+	burst       int
+	fingerprint Fingerprint
+}
+
+// CollapseOption configures WithErrorCollapsing.
+type CollapseOption func(*collapseOptions)
+
+// CollapseBurst sets how many occurrences of the same error are logged in each window before they are suppressed.
+// The default is 1.
+func CollapseBurst(burst int) CollapseOption {
+	return func(opts *collapseOptions) {
+		opts.burst = burst
+	}
+}
+
+// CollapseFingerprint sets the function that identifies repeated errors. The default is DefaultFingerprint.
+func CollapseFingerprint(fingerprint Fingerprint) CollapseOption {
+	return func(opts *collapseOptions) {
+		opts.fingerprint = fingerprint
+	}
+}
+
+// collapseSummary is the entry written when a window, in which errors were suppressed, ends.
+type collapseSummary struct {
+	logger  *zap.Logger
+	level   zapcore.Level
+	message string
+	fields  []zap.Field
+	naming  NamingScheme
+}
+
+type collapsedError struct {
+	count      int
+	suppressed int
+	summary    collapseSummary
+}
+
+// errorCollapser counts the occurrences of each error fingerprint within a window, starting at its first occurrence.
+type errorCollapser struct {
+	window  time.Duration
+	opts    collapseOptions
+	mu      sync.Mutex
+	entries map[string]*collapsedError
+}
+
+func newErrorCollapser(window time.Duration, options ...CollapseOption) *errorCollapser {
+	opts := collapseOptions{
+		burst:       1,
+		fingerprint: DefaultFingerprint,
+	}
+	for _, opt := range options {
+		opt(&opts)
+	}
+	return &errorCollapser{
+		window:  window,
+		opts:    opts,
+		entries: make(map[string]*collapsedError),
+	}
+}
+
+// suppress reports whether an occurrence of the error identified by fingerprint must not be logged. summary is called
+// for the first suppressed occurrence of each window, to build the entry written when the window ends.
+func (c *errorCollapser) suppress(fingerprint string, summary func() collapseSummary) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[fingerprint]
+	if !ok {
+		e = &collapsedError{}
+		c.entries[fingerprint] = e
+		time.AfterFunc(c.window, func() {
+			c.flush(fingerprint)
+		})
+	}
+	e.count++
+	if e.count <= c.opts.burst {
+		return false
+	}
+	if e.suppressed == 0 {
+		e.summary = summary()
+	}
+	e.suppressed++
+	return true
+}
+
+// flush ends the window of fingerprint, writing its summary when any occurrence was suppressed.
+func (c *errorCollapser) flush(fingerprint string) {
+	c.mu.Lock()
+	e := c.entries[fingerprint]
+	delete(c.entries, fingerprint)
+	c.mu.Unlock()
+
+	if e == nil || e.suppressed == 0 {
+		return
+	}
+	if ce := e.summary.logger.Check(e.summary.level, e.summary.message); ce != nil {
+		ce.Write(e.summary.naming.rename(append(
+			e.summary.fields,
+			zap.String(fieldGRPCErrorFingerprint, fingerprint),
+			zap.Int(fieldGRPCErrorSuppressed, e.suppressed),
+			zap.Duration(fieldGRPCErrorWindow, c.window),
+		))...)
+	}
+}
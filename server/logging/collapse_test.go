@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDefaultFingerprint(t *testing.T) {
+	assert.Equal(t,
+		DefaultFingerprint("/pkg.Service/Method", status.Error(codes.Unavailable, "down")),
+		DefaultFingerprint("/pkg.Service/Method", status.Error(codes.Unavailable, "down")),
+	)
+	assert.NotEqual(t,
+		DefaultFingerprint("/pkg.Service/Method", status.Error(codes.Unavailable, "down")),
+		DefaultFingerprint("/pkg.Service/Other", status.Error(codes.Unavailable, "down")),
+	)
+	assert.NotEqual(t,
+		DefaultFingerprint("/pkg.Service/Method", status.Error(codes.Unavailable, "down")),
+		DefaultFingerprint("/pkg.Service/Method", errors.New("down")),
+	)
+
+	withReason := func(msg, reason string) error {
+		st, err := status.New(codes.Unavailable, msg).WithDetails(&errdetails.ErrorInfo{Reason: reason, Domain: "example.com"})
+		require.NoError(t, err)
+		return st.Err()
+	}
+	assert.Equal(t,
+		DefaultFingerprint("/pkg.Service/Method", withReason("request 1 failed", "DB_DOWN")),
+		DefaultFingerprint("/pkg.Service/Method", withReason("request 2 failed", "DB_DOWN")),
+		"the message should be ignored when there is an ErrorInfo",
+	)
+}
+
+func TestWithErrorCollapsing(t *testing.T) {
+	ctx, obs := createObserver()
+	// The window is long enough to never end by itself during the test, it is ended by calling flush.
+	collapser := newErrorCollapser(time.Hour, CollapseBurst(2))
+	interceptor := UnaryInterceptor(func(opts *loggingOptions) {
+		opts.collapser = collapser
+	})
+	call := func(method string, err error) {
+		_, _ = interceptor(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: method,
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, err
+		})
+	}
+
+	for i := 0; i < 5; i++ {
+		call("/pkg.Service/Method", status.Error(codes.Unavailable, "down"))
+	}
+	call("/pkg.Service/Other", status.Error(codes.Unavailable, "down"))
+	call("/pkg.Service/Method", nil)
+	assert.Len(t, obs.All(), 4, "only the first 2 occurrences, the other method and the success should be logged")
+
+	collapser.flush(DefaultFingerprint("/pkg.Service/Method", status.Error(codes.Unavailable, "down")))
+	collapser.flush(DefaultFingerprint("/pkg.Service/Other", status.Error(codes.Unavailable, "down")))
+	require.Equal(t, 1, obs.FilterMessage("Method repeated errors suppressed").Len())
+	summary := obs.FilterMessage("Method repeated errors suppressed").All()[0]
+	fields := summary.ContextMap()
+	assert.Equal(t, int64(3), fields[fieldGRPCErrorSuppressed])
+	assert.Equal(t, "Unavailable", fields[fieldGRPCStatus])
+	assert.Equal(t, "Method", fields[fieldGRPCMethod])
+	assert.Equal(t, "down", fields[fieldGRPCErrorMessage])
+	assert.Equal(t, time.Hour, fields[fieldGRPCErrorWindow])
+	assert.Zero(t, obs.FilterMessage("Other repeated errors suppressed").Len(), "no summary without suppressed errors")
+
+	call("/pkg.Service/Method", status.Error(codes.Unavailable, "down"))
+	assert.Equal(t, 3, obs.FilterMessage("Method completed with error").Len(), "a new window should start")
+}
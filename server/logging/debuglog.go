@@ -0,0 +1,105 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/jamillosantos/logctx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	fieldGRPCDebugLog = "grpc.debug_log"
+
+	defaultDebugLogKey = "x-debug-log"
+)
+
+// DebugLogAuthorizer reports whether the caller of fullMethod is allowed to enable the debug logging of the call.
+// value is the value of the debug log metadata key, which can carry a token to be verified.
+type DebugLogAuthorizer func(ctx context.Context, fullMethod string, value string) bool
+
+type debugLogOptions struct {
+	key          string
+	authorize    DebugLogAuthorizer
+	protoOptions []ProtoOption
+}
+
+// DebugLogOption configures WithDebugLogHeader.
+type DebugLogOption func(*debugLogOptions)
+
+// DebugLogKey sets the metadata key that enables the debug logging of a call. The default is "x-debug-log".
+func DebugLogKey(key string) DebugLogOption {
+	return func(opts *debugLogOptions) {
+		opts.key = key
+	}
+}
+
+// DebugLogProtoOptions sets the options of the proto extractors used to log the payloads of the calls with debug
+// logging enabled, when no request or response extractor is configured. By default, the payloads are redacted with the
+// redactor set by WithRedactor or, when there is none, the fields annotated with `debug_redact` are redacted.
+func DebugLogProtoOptions(options ...ProtoOption) DebugLogOption {
+	return func(opts *debugLogOptions) {
+		opts.protoOptions = options
+	}
+}
+
+// withDebugLog enables the debug logging of the call when its incoming metadata carries the debug log key and the
+// caller is authorized: the logctx logger of the call is raised to the debug level and the completion of the call,
+// with its payloads, is logged. It returns the options to be used by the call.
+func withDebugLog(ctx context.Context, fullMethod string, opts loggingOptions) (context.Context, loggingOptions) {
+	if opts.debugLog == nil {
+		return ctx, opts
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(opts.debugLog.key)
+	if len(values) == 0 || values[0] == "" || !opts.debugLog.authorize(ctx, fullMethod, values[0]) {
+		return ctx, opts
+	}
+
+	logger := levelLogger(logctx.From(ctx), zapcore.DebugLevel)
+	ctx = logctx.WithLogger(ctx, logger.With(zap.Bool(fieldGRPCDebugLog, true)))
+
+	opts.logResponse = true
+	// The options given by DebugLogProtoOptions come last, so they can replace the redactor.
+	protoOptions := append([]ProtoOption{ProtoRedactor(payloadRedactor(opts))}, opts.debugLog.protoOptions...)
+	if opts.extractRequest == nil {
+		opts.extractRequest = ProtoRequestExtractor(protoOptions...)
+	}
+	if opts.extractResponse == nil {
+		opts.extractResponse = ProtoResponseExtractor(protoOptions...)
+	}
+	return ctx, opts
+}
+
+// levelCore writes the entries at or above level, including the ones the wrapped core is not enabled for, and drops
+// the others.
+type levelCore struct {
+	zapcore.Core
+	level zapcore.Level
+}
+
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), level: c.level}
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.level.Enabled(entry.Level) {
+		return ce
+	}
+	if c.Core.Enabled(entry.Level) {
+		return c.Core.Check(entry, ce)
+	}
+	return ce.AddCore(entry, c)
+}
+
+// levelLogger returns logger with its level set to level, which can be lower than the level of its core.
+func levelLogger(logger *zap.Logger, level zapcore.Level) *zap.Logger {
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &levelCore{Core: core, level: level}
+	}))
+}
@@ -0,0 +1,99 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jamillosantos/logctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestWithDebugLogHeader(t *testing.T) {
+	authorize := func(ctx context.Context, fullMethod string, value string) bool {
+		return value == "secret"
+	}
+	call := func(interceptor grpc.UnaryServerInterceptor, md metadata.MD) *observer.ObservedLogs {
+		ctx := context.Background()
+		if md != nil {
+			ctx = metadata.NewIncomingContext(ctx, md)
+		}
+		return callMethod(ctx, zapcore.InfoLevel, interceptor, wrapperspb.String("request"), func(ctx context.Context, req interface{}) (interface{}, error) {
+			logctx.From(ctx).Debug("debug entry")
+			return wrapperspb.String("response"), nil
+		})
+	}
+
+	t.Run("should enable the debug logging for authorized calls", func(t *testing.T) {
+		obs := call(UnaryInterceptor(WithOperationCompleted(false), WithDebugLogHeader(authorize)), metadata.Pairs("x-debug-log", "secret"))
+
+		debug := obs.FilterMessage("debug entry").All()
+		require.Len(t, debug, 1)
+		assert.Equal(t, true, debug[0].ContextMap()[fieldGRPCDebugLog])
+
+		completed := obs.FilterMessage("Method completed").All()
+		require.Len(t, completed, 1)
+		fields := completed[0].ContextMap()
+		assert.Equal(t, true, fields[fieldGRPCDebugLog])
+		assert.Equal(t, map[string]interface{}{"value": "request"}, fields[fieldGRPCRequest])
+		assert.Equal(t, map[string]interface{}{"value": "response"}, fields[fieldGRPCResponse])
+	})
+
+	t.Run("should redact the payloads", func(t *testing.T) {
+		md, _ := createRedactTestTypes(t)
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-debug-log", "secret"))
+		obs := callMethod(ctx, zapcore.InfoLevel, UnaryInterceptor(WithDebugLogHeader(authorize)), newRedactTestUser(md), func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+
+		request := obs.FilterMessage("Method completed").All()[0].ContextMap()[fieldGRPCRequest].(map[string]interface{})
+		assert.Equal(t, "john", request["name"])
+		assert.Equal(t, redactedValue, request["password"])
+	})
+
+	t.Run("should not enable the debug logging for unauthorized calls", func(t *testing.T) {
+		obs := call(UnaryInterceptor(WithOperationCompleted(false), WithDebugLogHeader(authorize)), metadata.Pairs("x-debug-log", "guess"))
+
+		assert.Zero(t, obs.Len())
+	})
+
+	t.Run("should not enable the debug logging without the metadata key", func(t *testing.T) {
+		called := false
+		obs := call(UnaryInterceptor(WithDebugLogHeader(func(ctx context.Context, fullMethod string, value string) bool {
+			called = true
+			return true
+		})), nil)
+
+		assert.False(t, called, "the authorizer should only be called when the key is present")
+		assert.Zero(t, obs.FilterMessage("debug entry").Len())
+		completed := obs.FilterMessage("Method completed").All()
+		require.Len(t, completed, 1)
+		assert.NotContains(t, completed[0].ContextMap(), fieldGRPCRequest)
+	})
+
+	t.Run("should panic without an authorizer", func(t *testing.T) {
+		assert.Panics(t, func() {
+			WithDebugLogHeader(nil)
+		})
+	})
+
+	t.Run("should use the configured key", func(t *testing.T) {
+		obs := call(UnaryInterceptor(WithDebugLogHeader(authorize, DebugLogKey("x-verbose"))), metadata.Pairs("x-verbose", "secret"))
+
+		assert.Equal(t, 1, obs.FilterMessage("debug entry").Len())
+	})
+
+	t.Run("should not change the calls without the metadata key", func(t *testing.T) {
+		interceptor := UnaryInterceptor(WithDebugLogHeader(authorize))
+		_ = call(interceptor, metadata.Pairs("x-debug-log", "secret"))
+		obs := call(interceptor, nil)
+
+		assert.Zero(t, obs.FilterMessage("debug entry").Len())
+		assert.NotContains(t, obs.FilterMessage("Method completed").All()[0].ContextMap(), fieldGRPCRequest)
+	})
+}
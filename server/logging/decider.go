@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"context"
+	"path"
+	"regexp"
+)
+
+// Decision defines what the interceptor does with the log entries of a call.
+type Decision int
+
+const (
+	// DecisionLog logs the call as configured.
+	DecisionLog Decision = iota
+	// DecisionSkip does not log anything about the call.
+	DecisionSkip
+	// DecisionLogOnError only logs the completion of the call when it fails.
+	DecisionLogOnError
+	// DecisionDowngrade logs the call as configured, but at the Debug level.
+	DecisionDowngrade
+)
+
+// Decider decides how a call is logged. It is called before the call is handled, with a nil err, and again when the
+// call completes with its final error.
+type Decider func(ctx context.Context, fullMethod string, err error) Decision
+
+// MethodMatcher reports whether the full method (eg: "/package.Service/Method") matches some criteria.
+type MethodMatcher func(fullMethod string) bool
+
+// MatchMethods matches the given full methods exactly.
+func MatchMethods(fullMethods ...string) MethodMatcher {
+	set := make(map[string]struct{}, len(fullMethods))
+	for _, m := range fullMethods {
+		set[m] = struct{}{}
+	}
+	return func(fullMethod string) bool {
+		_, ok := set[fullMethod]
+		return ok
+	}
+}
+
+// MatchGlob matches the full method against a path.Match pattern (eg: "/grpc.health.v1.Health/*"). It panics if the
+// pattern is malformed.
+func MatchGlob(pattern string) MethodMatcher {
+	if _, err := path.Match(pattern, ""); err != nil {
+		panic("logging: invalid glob pattern " + pattern + ": " + err.Error())
+	}
+	return func(fullMethod string) bool {
+		ok, _ := path.Match(pattern, fullMethod)
+		return ok
+	}
+}
+
+// MatchRegexp matches the full method against a regular expression.
+func MatchRegexp(re *regexp.Regexp) MethodMatcher {
+	return re.MatchString
+}
+
+// MethodDecider returns a Decider that applies decision to the methods matched by matcher.
+func MethodDecider(matcher MethodMatcher, decision Decision) Decider {
+	return func(_ context.Context, fullMethod string, _ error) Decision {
+		if matcher(fullMethod) {
+			return decision
+		}
+		return DecisionLog
+	}
+}
+
+// decide returns the first decision, other than DecisionLog, returned by the deciders.
+func decide(deciders []Decider, ctx context.Context, fullMethod string, err error) Decision {
+	for _, decider := range deciders {
+		if d := decider(ctx, fullMethod, err); d != DecisionLog {
+			return d
+		}
+	}
+	return DecisionLog
+}
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+)
+
+func TestMethodMatchers(t *testing.T) {
+	assert.True(t, MatchMethods("/pkg.Service/A", "/pkg.Service/B")("/pkg.Service/B"))
+	assert.False(t, MatchMethods("/pkg.Service/A")("/pkg.Service/B"))
+	assert.True(t, MatchGlob("/grpc.health.v1.Health/*")("/grpc.health.v1.Health/Check"))
+	assert.False(t, MatchGlob("/grpc.health.v1.Health/*")("/pkg.Service/Check"))
+	assert.Panics(t, func() { MatchGlob("[") })
+	assert.True(t, MatchRegexp(regexp.MustCompile(`^/grpc\.reflection\.`))("/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo"))
+}
+
+func TestInterceptor_Deciders(t *testing.T) {
+	handlerErr := errors.New("failed")
+	tests := []struct {
+		name        string
+		decision    Decision
+		err         error
+		wantEntries int
+		wantLevel   zapcore.Level
+	}{
+		{"skip", DecisionSkip, handlerErr, 0, 0},
+		{"log on error when succeeding", DecisionLogOnError, nil, 0, 0},
+		{"log on error when failing", DecisionLogOnError, handlerErr, 1, zapcore.ErrorLevel},
+		{"downgrade", DecisionDowngrade, nil, 2, zapcore.DebugLevel},
+		{"log", DecisionLog, nil, 2, zapcore.InfoLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, obs := createObserver()
+			_, _ = UnaryInterceptor(
+				WithOperationStarted(true),
+				WithMethodDecision(MatchGlob("/grpc.health.v1.Health/*"), tt.decision),
+			)(ctx, nil, &grpc.UnaryServerInfo{
+				FullMethod: "/grpc.health.v1.Health/Check",
+			}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, tt.err
+			})
+			entries := obs.All()
+			require.Len(t, entries, tt.wantEntries)
+			for _, entry := range entries {
+				assert.Equal(t, tt.wantLevel, entry.Level)
+			}
+		})
+	}
+
+	t.Run("should pass the final error to the decider", func(t *testing.T) {
+		var gotErrs []error
+		ctx, _ := createObserver()
+		_, _ = UnaryInterceptor(
+			WithDecider(func(ctx context.Context, fullMethod string, err error) Decision {
+				assert.Equal(t, "/pkg.Service/Method", fullMethod)
+				gotErrs = append(gotErrs, err)
+				return DecisionLog
+			}),
+		)(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, handlerErr
+		})
+		assert.Equal(t, []error{nil, handlerErr}, gotErrs)
+	})
+}
@@ -0,0 +1,31 @@
+package logging
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DurationField creates the field used to log durations (the call duration and the remaining deadline).
+type DurationField func(key string, d time.Duration) zap.Field
+
+// DurationAsDuration logs the duration as a zap.Duration, leaving its encoding to the zapcore.EncoderConfig of the
+// logger.
+func DurationAsDuration(key string, d time.Duration) zap.Field {
+	return zap.Duration(key, d)
+}
+
+// DurationAsMilliseconds logs the duration as a float number of milliseconds.
+func DurationAsMilliseconds(key string, d time.Duration) zap.Field {
+	return zap.Float64(key, float64(d)/float64(time.Millisecond))
+}
+
+// DurationAsSeconds logs the duration as a float number of seconds.
+func DurationAsSeconds(key string, d time.Duration) zap.Field {
+	return zap.Float64(key, d.Seconds())
+}
+
+// DurationAsNanoseconds logs the duration as an integer number of nanoseconds.
+func DurationAsNanoseconds(key string, d time.Duration) zap.Field {
+	return zap.Int64(key, d.Nanoseconds())
+}
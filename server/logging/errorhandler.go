@@ -2,179 +2,86 @@ package logging
 
 import (
 	"context"
-	"fmt"
 
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
-	"google.golang.org/genproto/googleapis/rpc/errdetails"
-	"google.golang.org/grpc/status"
-	"google.golang.org/protobuf/proto"
-)
-
-func defaultHandleError(_ context.Context, err error) []zap.Field {
-	if err == nil {
-		return nil
-	}
-	fields := []zap.Field{zap.Error(err)}
-	if st, ok := status.FromError(err); ok {
-		fields = append(fields, zap.String(fieldGRPCErrorMessage, st.Message()))
-		details := st.Details()
-		if len(details) > 0 {
-			fields = append(fields, zap.Array(fieldGRPCErrorDetails, &errorDetailsObjectMarshaler{details}))
-		}
-	}
-	return fields
-}
-
-type errorDetailsObjectMarshaler struct {
-	details []any
-}
-
-func (e errorDetailsObjectMarshaler) MarshalLogArray(encoder zapcore.ArrayEncoder) error {
-	for _, detail := range e.details {
-		switch detail.(type) {
-		case *errdetails.BadRequest, *errdetails.QuotaFailure, *errdetails.RequestInfo, *errdetails.ResourceInfo, *errdetails.DebugInfo, *errdetails.Help, *errdetails.LocalizedMessage, *errdetails.PreconditionFailure, *errdetails.RetryInfo, *errdetails.ErrorInfo:
-			encoder.AppendObject(&ErrDetailObjectMarshaler{detail.(proto.Message)})
-		default:
-			encoder.AppendReflected(detail)
-		}
-	}
-	return nil
-}
-
-type ErrDetailObjectMarshaler struct {
-	detail proto.Message
-}
-
-func (e ErrDetailObjectMarshaler) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
-	encoder.AddString("$type", string(proto.MessageName(e.detail).Name()))
-	switch v := e.detail.(type) {
-	case *errdetails.BadRequest:
-		encoder.AddArray("field_violations", &fieldViolationsObjectMarshaler{v.FieldViolations})
-	case *errdetails.QuotaFailure:
-		encoder.AddArray("violations", &quotaViolationsObjectMarshaler{v.Violations})
-	case *errdetails.RequestInfo:
-		encoder.AddString("request_id", v.RequestId)
-		encoder.AddString("serving_data", v.ServingData)
-	case *errdetails.ResourceInfo:
-		encoder.AddString("resource_type", v.ResourceType)
-		encoder.AddString("resource_name", v.ResourceName)
-		encoder.AddString("owner", v.Owner)
-		encoder.AddString("description", v.Description)
-	case *errdetails.DebugInfo:
-		encoder.AddArray("stack_entries", &stringsArrayMarshaler{v.StackEntries})
-		encoder.AddString("detail", v.Detail)
-	case *errdetails.Help:
-		encoder.AddArray("links", &linksObjectMarshaler{v.Links})
-	case *errdetails.LocalizedMessage:
-		encoder.AddString("locale", v.Locale)
-		encoder.AddString("message", v.Message)
-	case *errdetails.PreconditionFailure:
-		encoder.AddArray("violations", &preconditionViolationsObjectMarshaler{v.Violations})
-	case *errdetails.RetryInfo:
-		encoder.AddString("retry_delay", v.RetryDelay.String())
-	case *errdetails.ErrorInfo:
-		encoder.AddString("reason", v.Reason)
-		encoder.AddString("domain", v.Domain)
-		for k, v := range v.Metadata {
-			encoder.AddString(fmt.Sprintf("metadata.%s", k), v)
-		}
-	}
-	return nil
-}
-
-type quotaViolationsObjectMarshaler struct {
-	violations []*errdetails.QuotaFailure_Violation
-}
+	"google.golang.org/protobuf/reflect/protoreflect"
 
-func (q quotaViolationsObjectMarshaler) MarshalLogArray(encoder zapcore.ArrayEncoder) error {
-	for _, violation := range q.violations {
-		encoder.AppendObject(&quotaViolationObjectMarshaler{violation})
-	}
-	return nil
-}
-
-type quotaViolationObjectMarshaler struct {
-	violation *errdetails.QuotaFailure_Violation
-}
-
-func (q quotaViolationObjectMarshaler) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
-	encoder.AddString("subject", q.violation.Subject)
-	encoder.AddString("description", q.violation.Description)
-	return nil
-}
+	"github.com/jamillosantos/go-grpc-interceptors/internal/logfields"
+)
 
-type fieldViolationsObjectMarshaler struct {
-	fieldViolations []*errdetails.BadRequest_FieldViolation
-}
+// ErrDetailObjectMarshaler renders the well known errdetails messages as zap objects.
+type ErrDetailObjectMarshaler = logfields.ErrDetailObjectMarshaler
 
-func (f fieldViolationsObjectMarshaler) MarshalLogArray(encoder zapcore.ArrayEncoder) error {
-	for _, fieldViolation := range f.fieldViolations {
-		encoder.AppendObject(&fieldViolationObjectMarshaler{fieldViolation})
-	}
-	return nil
-}
+// DetailMarshaler renders a status detail as a zap object. See ErrorDetailMarshaler.
+type DetailMarshaler = logfields.DetailMarshaler
 
-type fieldViolationObjectMarshaler struct {
-	fieldViolation *errdetails.BadRequest_FieldViolation
-}
+// DetailResolver finds the message types of the status details. *protoregistry.Types implements it.
+type DetailResolver = logfields.DetailResolver
 
-func (f fieldViolationObjectMarshaler) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
-	encoder.AddString("field", f.fieldViolation.Field)
-	encoder.AddString("description", f.fieldViolation.Description)
-	return nil
-}
+// ErrorHandlerOption configures the error handler created by NewErrorHandler.
+type ErrorHandlerOption = logfields.ErrorHandlerOption
 
-type stringsArrayMarshaler struct {
-	arr []string
+// NewErrorHandler creates an error handler, to be used with WithErrorHandler, that logs the error and, when it carries
+// a gRPC status, its message and details. Details are rendered by the marshaler registered for their message name
+// (see ErrorDetailMarshaler), by ErrDetailObjectMarshaler for the well known errdetails messages or, for any other
+// message, with the structure of its protojson encoding. Every detail carries its message name under "$type" (the
+// short name for the errdetails messages and the full name for any other) and the details whose type cannot be
+// resolved are logged with their raw value.
+func NewErrorHandler(options ...ErrorHandlerOption) func(ctx context.Context, err error) []zap.Field {
+	return logfields.NewErrorHandler(options...).HandleError
 }
 
-func (s stringsArrayMarshaler) MarshalLogArray(encoder zapcore.ArrayEncoder) error {
-	for _, str := range s.arr {
-		encoder.AppendString(str)
-	}
-	return nil
+// ErrorDetailResolver sets the resolver used to find the message types of the status details, such as a
+// *protoregistry.Types holding dynamic messages. The types it does not know are still resolved through
+// protoregistry.GlobalTypes.
+func ErrorDetailResolver(resolver DetailResolver) ErrorHandlerOption {
+	return logfields.WithDetailResolver(resolver)
 }
 
-type linksObjectMarshaler struct {
-	links []*errdetails.Help_Link
+// ErrorDetailMarshaler renders the status details of the given message type with marshaler (eg: to use ProtoMessage
+// with redaction or size limits, or a hand written zapcore.ObjectMarshaler).
+func ErrorDetailMarshaler(name protoreflect.FullName, marshaler DetailMarshaler) ErrorHandlerOption {
+	return logfields.WithDetailMarshaler(name, marshaler)
 }
 
-func (l linksObjectMarshaler) MarshalLogArray(encoder zapcore.ArrayEncoder) error {
-	for _, link := range l.links {
-		encoder.AppendObject(&linkObjectMarshaler{link})
-	}
-	return nil
-}
+// ErrorHandlerFunc renders the error returned by the handler into the fields of the completed entry.
+type ErrorHandlerFunc = func(ctx context.Context, err error) []zap.Field
 
-type linkObjectMarshaler struct {
-	link *errdetails.Help_Link
-}
+// The kinds of error, logged under "grpc.error.kind" by the default error handler. See ClassifyError.
+const (
+	ErrorKindCanceled         = logfields.ErrorKindCanceled
+	ErrorKindDeadlineExceeded = logfields.ErrorKindDeadlineExceeded
+	ErrorKindStatus           = logfields.ErrorKindStatus
+	ErrorKindWrappedStatus    = logfields.ErrorKindWrappedStatus
+	ErrorKindPlain            = logfields.ErrorKindPlain
+)
 
-func (l linkObjectMarshaler) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
-	encoder.AddString("description", l.link.Description)
-	encoder.AddString("url", l.link.Url)
-	return nil
+// ClassifyError tells whether err is caused by the cancellation of the call by the client (ErrorKindCanceled), by
+// its deadline being exceeded (ErrorKindDeadlineExceeded), is a gRPC status (ErrorKindStatus), wraps a gRPC status
+// found with errors.As (ErrorKindWrappedStatus) or is a plain Go error (ErrorKindPlain).
+func ClassifyError(ctx context.Context, err error) string {
+	return logfields.ClassifyError(ctx, err)
 }
 
-type preconditionViolationsObjectMarshaler struct {
-	violations []*errdetails.PreconditionFailure_Violation
+// DefaultErrorHandler is the error handler used when none is set. It logs the error, its kind (see ClassifyError)
+// and, when the error carries a gRPC status, its message and details.
+func DefaultErrorHandler(ctx context.Context, err error) []zap.Field {
+	return logfields.HandleError(ctx, err)
 }
 
-func (p preconditionViolationsObjectMarshaler) MarshalLogArray(encoder zapcore.ArrayEncoder) error {
-	for _, violation := range p.violations {
-		encoder.AppendObject(&preconditionViolationObjectMarshaler{violation})
+// ChainErrorHandlers returns an error handler that logs the fields of all the given handlers, in order.
+func ChainErrorHandlers(handlers ...ErrorHandlerFunc) ErrorHandlerFunc {
+	return func(ctx context.Context, err error) []zap.Field {
+		var fields []zap.Field
+		for _, handler := range handlers {
+			if handler != nil {
+				fields = append(fields, handler(ctx, err)...)
+			}
+		}
+		return fields
 	}
-	return nil
-}
-
-type preconditionViolationObjectMarshaler struct {
-	violation *errdetails.PreconditionFailure_Violation
 }
 
-func (p preconditionViolationObjectMarshaler) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
-	encoder.AddString("type", p.violation.Type)
-	encoder.AddString("subject", p.violation.Subject)
-	encoder.AddString("description", p.violation.Description)
-	return nil
+func defaultHandleError(ctx context.Context, err error) []zap.Field {
+	return DefaultErrorHandler(ctx, err)
 }
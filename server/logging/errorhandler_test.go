@@ -0,0 +1,207 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func handleErrorFields(t *testing.T, handler func(ctx context.Context, err error) []zapcore.Field, err error) map[string]interface{} {
+	t.Helper()
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range handler(context.Background(), err) {
+		f.AddTo(enc)
+	}
+	return enc.Fields
+}
+
+func TestNewErrorHandler(t *testing.T) {
+	md, _ := createRedactTestTypes(t)
+	user := newRedactTestUser(md)
+	userAny, err := anypb.New(user)
+	require.NoError(t, err)
+
+	types := new(protoregistry.Types)
+	require.NoError(t, types.RegisterMessage(dynamicpb.NewMessageType(md)))
+	require.NoError(t, types.RegisterMessage(dynamicpb.NewMessageType(md.Fields().ByName("cards").Message())))
+
+	errWithDetails := func(details ...*anypb.Any) error {
+		return status.FromProto(&spb.Status{Code: int32(codes.Internal), Message: "failure", Details: details}).Err()
+	}
+
+	t.Run("should render any detail resolved by the resolver", func(t *testing.T) {
+		fields := handleErrorFields(t, NewErrorHandler(ErrorDetailResolver(types)), errWithDetails(userAny))
+		assert.Equal(t, []interface{}{map[string]interface{}{
+			"$type":    "test.User",
+			"name":     "john",
+			"password": "secret",
+			"token":    "token",
+			"cards": []interface{}{
+				map[string]interface{}{"number": "4111", "holder": "john"},
+			},
+		}}, fields[fieldGRPCErrorDetails])
+	})
+
+	t.Run("should render well known types through the global registry", func(t *testing.T) {
+		ts, err := anypb.New(timestamppb.New(time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)))
+		require.NoError(t, err)
+		fields := handleErrorFields(t, NewErrorHandler(), errWithDetails(ts))
+		assert.Equal(t, []interface{}{map[string]interface{}{
+			"$type": "google.protobuf.Timestamp",
+			"value": "2022-10-01T12:00:00Z",
+		}}, fields[fieldGRPCErrorDetails])
+	})
+
+	t.Run("should render unresolved details as their message name and value", func(t *testing.T) {
+		fields := handleErrorFields(t, NewErrorHandler(), errWithDetails(userAny))
+		assert.Equal(t, []interface{}{map[string]interface{}{
+			"$type": "test.User",
+			"value": userAny.Value,
+		}}, fields[fieldGRPCErrorDetails])
+	})
+
+	t.Run("should use the custom marshalers", func(t *testing.T) {
+		r, err := NewRedactor()
+		require.NoError(t, err)
+		handler := NewErrorHandler(
+			ErrorDetailResolver(types),
+			ErrorDetailMarshaler("test.User", func(detail proto.Message) zapcore.ObjectMarshaler {
+				return ProtoMessage(detail, ProtoRedactor(r))
+			}),
+			ErrorDetailMarshaler("google.rpc.ErrorInfo", func(detail proto.Message) zapcore.ObjectMarshaler {
+				return zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+					enc.AddString("reason", detail.(*errdetails.ErrorInfo).Reason)
+					return nil
+				})
+			}),
+		)
+		info, err := anypb.New(&errdetails.ErrorInfo{Reason: "REASON", Domain: "example.com"})
+		require.NoError(t, err)
+
+		details := handleErrorFields(t, handler, errWithDetails(userAny, info))[fieldGRPCErrorDetails].([]interface{})
+		require.Len(t, details, 2)
+		assert.Equal(t, redactedValue, details[0].(map[string]interface{})["password"])
+		assert.Equal(t, map[string]interface{}{"reason": "REASON"}, details[1])
+	})
+
+	t.Run("should keep rendering the errdetails messages", func(t *testing.T) {
+		info, err := anypb.New(&errdetails.RequestInfo{RequestId: "123"})
+		require.NoError(t, err)
+		fields := handleErrorFields(t, NewErrorHandler(), errWithDetails(info))
+		assert.Equal(t, []interface{}{map[string]interface{}{
+			"$type":        "RequestInfo",
+			"request_id":   "123",
+			"serving_data": "",
+		}}, fields[fieldGRPCErrorDetails])
+	})
+}
+
+type wrappedError struct {
+	err error
+}
+
+func (w wrappedError) Error() string { return "wrapped: " + w.err.Error() }
+func (w wrappedError) Unwrap() error { return w.err }
+
+func TestClassifyError(t *testing.T) {
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ctx := context.Background()
+	assert.Equal(t, ErrorKindCanceled, ClassifyError(ctx, context.Canceled))
+	assert.Equal(t, ErrorKindCanceled, ClassifyError(ctx, status.Error(codes.Canceled, "canceled")))
+	assert.Equal(t, ErrorKindCanceled, ClassifyError(canceledCtx, errors.New("failure")))
+	assert.Equal(t, ErrorKindDeadlineExceeded, ClassifyError(ctx, wrappedError{context.DeadlineExceeded}))
+	assert.Equal(t, ErrorKindDeadlineExceeded, ClassifyError(ctx, status.Error(codes.DeadlineExceeded, "deadline")))
+	assert.Equal(t, ErrorKindStatus, ClassifyError(ctx, status.Error(codes.NotFound, "not found")))
+	assert.Equal(t, ErrorKindWrappedStatus, ClassifyError(ctx, wrappedError{status.Error(codes.NotFound, "not found")}))
+	assert.Equal(t, ErrorKindPlain, ClassifyError(ctx, errors.New("failure")))
+	assert.Equal(t, ErrorKindDeadlineExceeded, ClassifyError(ctx, wrappedError{status.Error(codes.DeadlineExceeded, "deadline")}))
+
+	st, err := status.New(codes.NotFound, "not found").WithDetails(&errdetails.ResourceInfo{ResourceName: "user"})
+	require.NoError(t, err)
+	fields := handleErrorFields(t, DefaultErrorHandler, wrappedError{st.Err()})
+	assert.Equal(t, ErrorKindWrappedStatus, fields[fieldGRPCErrorKind])
+	assert.Equal(t, "not found", fields[fieldGRPCErrorMessage], "the message of the wrapped status should be logged")
+	assert.Len(t, fields[fieldGRPCErrorDetails], 1)
+}
+
+func TestInterceptor_WrappedStatus(t *testing.T) {
+	call := func(handlerErr error) observer.LoggedEntry {
+		ctx, obs := createObserver()
+		_, _ = UnaryInterceptor()(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, handlerErr
+		})
+		entries := obs.All()
+		require.Len(t, entries, 1)
+		return entries[0]
+	}
+
+	t.Run("should log the code sent by gRPC for a wrapped status", func(t *testing.T) {
+		entry := call(wrappedError{status.Error(codes.NotFound, "not found")})
+		assert.Equal(t, zapcore.ErrorLevel, entry.Level, "gRPC sends wrapped statuses as Unknown")
+		fields := entry.ContextMap()
+		assert.Equal(t, "Unknown", fields[fieldGRPCStatus])
+		assert.Equal(t, uint32(codes.Unknown), fields[fieldGRPCStatusCode])
+		assert.Equal(t, "not found", fields[fieldGRPCErrorMessage])
+		assert.Equal(t, ErrorKindWrappedStatus, fields[fieldGRPCErrorKind])
+		assert.Equal(t, "wrapped: rpc error: code = NotFound desc = not found", fields["error"])
+	})
+
+	t.Run("should log the code of the context errors", func(t *testing.T) {
+		fields := call(context.Canceled).ContextMap()
+		assert.Equal(t, "Canceled", fields[fieldGRPCStatus])
+		assert.Equal(t, uint32(codes.Canceled), fields[fieldGRPCStatusCode])
+
+		fields = call(wrappedError{context.DeadlineExceeded}).ContextMap()
+		assert.Equal(t, "DeadlineExceeded", fields[fieldGRPCStatus])
+		assert.Equal(t, uint32(codes.DeadlineExceeded), fields[fieldGRPCStatusCode])
+	})
+}
+
+func TestWithAdditionalErrorHandler(t *testing.T) {
+	ctx, obs := createObserver()
+	_, _ = UnaryInterceptor(
+		WithAdditionalErrorHandler(func(ctx context.Context, err error) []zapcore.Field {
+			return []zapcore.Field{zap.Bool("retryable", status.Code(err) == codes.Unavailable)}
+		}),
+	)(ctx, nil, &grpc.UnaryServerInfo{
+		FullMethod: "/pkg.Service/Method",
+	}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Unavailable, "down")
+	})
+	entries := obs.All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, true, fields["retryable"])
+	assert.Equal(t, "down", fields[fieldGRPCErrorMessage], "the default handler should be kept")
+	assert.Equal(t, ErrorKindStatus, fields[fieldGRPCErrorKind])
+}
+
+func TestChainErrorHandlers(t *testing.T) {
+	handler := ChainErrorHandlers(
+		func(ctx context.Context, err error) []zapcore.Field { return []zapcore.Field{zap.String("a", "1")} },
+		nil,
+		func(ctx context.Context, err error) []zapcore.Field { return []zapcore.Field{zap.String("b", "2")} },
+	)
+	assert.Equal(t, map[string]interface{}{"a": "1", "b": "2"}, handleErrorFields(t, handler, errors.New("failure")))
+}
@@ -3,38 +3,42 @@ package logging
 import (
 	"context"
 	"fmt"
-	"strings"
+	"time"
 
 	"github.com/jamillosantos/logctx"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
-)
-
-type contextAddFieldUpdater string
+	"google.golang.org/grpc/metadata"
 
-func (s contextAddFieldUpdater) String() string {
-	return string(s) + "contextAddFieldUpdater"
-}
+	"github.com/jamillosantos/go-grpc-interceptors/internal/logfields"
+)
 
 const (
-	fieldGRPCService      = "grpc.service"
-	fieldGRPCMethod       = "grpc.method"
-	fieldGRPCFullMethod   = "grpc.full_method"
-	fieldGRPCStatus       = "grpc.status"
-	fieldGRPCStatusCode   = "grpc.status_code"
-	fieldGRPCRequest      = "grpc.request"
-	fieldGRPCResponse     = "grpc.response"
-	fieldGRPCErrorMessage = "grpc.error.message"
-	fieldGRPCErrorDetails = "grpc.error.details"
+	fieldGRPCService         = "grpc.service"
+	fieldGRPCMethod          = "grpc.method"
+	fieldGRPCFullMethod      = "grpc.full_method"
+	fieldGRPCStatus          = "grpc.status"
+	fieldGRPCStatusCode      = "grpc.status_code"
+	fieldGRPCRequest         = "grpc.request"
+	fieldGRPCResponse        = "grpc.response"
+	fieldGRPCErrorMessage    = logfields.FieldGRPCErrorMessage
+	fieldGRPCErrorDetails    = logfields.FieldGRPCErrorDetails
+	fieldGRPCErrorKind       = logfields.FieldGRPCErrorKind
+	fieldGRPCMsgsReceived    = "grpc.stream.msgs_received"
+	fieldGRPCMsgsSent        = "grpc.stream.msgs_sent"
+	fieldGRPCStartTime       = "grpc.start_time"
+	fieldGRPCDuration        = "grpc.duration"
+	fieldGRPCDeadlineAtStart = "grpc.deadline.remaining_at_start"
+	fieldGRPCDeadlineAtEnd   = "grpc.deadline.remaining_at_end"
 )
 
 const (
 	messageRequest       = "%s started"
 	messageResponse      = "%s completed"
 	messageResponseError = "%s completed with error"
+	messageStreamRecv    = "%s message received"
+	messageStreamSend    = "%s message sent"
 )
 
 type loggingOptions struct {
@@ -42,10 +46,28 @@ type loggingOptions struct {
 	extractResponse      func(ctx context.Context, resp interface{}) zapcore.ObjectMarshaler
 	handleError          func(ctx context.Context, err error) []zap.Field
 	logRequest           bool
-	requestMessage       string
+	requestMessage       *messageTemplate
 	logResponse          bool
-	responseMessage      string
-	responseErrorMessage string
+	responseMessage      *messageTemplate
+	responseErrorMessage *messageTemplate
+	durationField        DurationField
+	levels               levels
+	deciders             []Decider
+	redactor             *Redactor
+	peerFields           bool
+	metadata             *metadataOptions
+	requestID            *requestIDOptions
+	traceContext         bool
+	contextLogger        bool
+	logger               *zap.Logger
+	naming               NamingScheme
+	collapser            *errorCollapser
+	debugBuffer          *debugBufferOptions
+	slow                 slowOptions
+	debugLog             *debugLogOptions
+	runtimeConfig        *RuntimeConfig
+	// protoPayloads are the options given to WithProtoPayloads, nil when it is not used.
+	protoPayloads []ProtoOption
 }
 
 type Option func(*loggingOptions)
@@ -56,19 +78,45 @@ func defaultOptions() loggingOptions {
 		extractResponse:      nil,
 		handleError:          defaultHandleError,
 		logRequest:           false,
-		requestMessage:       messageRequest,
+		requestMessage:       mustParseMessageTemplate(messageRequest, false),
 		logResponse:          true,
-		responseMessage:      messageResponse,
-		responseErrorMessage: messageResponseError,
+		responseMessage:      mustParseMessageTemplate(messageResponse, true),
+		responseErrorMessage: mustParseMessageTemplate(messageResponseError, true),
+		durationField:        DurationAsDuration,
+		levels:               defaultLevels(),
+		contextLogger:        true,
+		slow:                 defaultSlowOptions(),
 	}
 }
 
-func UnaryInterceptor(options ...Option) grpc.UnaryServerInterceptor {
+// newOptions applies options to the default options.
+func newOptions(options []Option) loggingOptions {
 	opts := defaultOptions()
 	for _, opt := range options {
 		opt(&opts)
 	}
+	if opts.protoPayloads != nil {
+		// The options given to WithProtoPayloads come last, so they can replace the redactor.
+		protoOptions := append([]ProtoOption{ProtoRedactor(payloadRedactor(opts))}, opts.protoPayloads...)
+		if opts.extractRequest == nil {
+			opts.extractRequest = ProtoRequestExtractor(protoOptions...)
+		}
+		if opts.extractResponse == nil {
+			opts.extractResponse = ProtoResponseExtractor(protoOptions...)
+		}
+	}
+	return opts
+}
+
+func UnaryInterceptor(options ...Option) grpc.UnaryServerInterceptor {
+	opts := newOptions(options)
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		start := time.Now()
+		if opts.logger != nil {
+			ctx = logctx.WithLogger(ctx, opts.logger)
+		}
+		ctx, opts := withRuntimeConfig(ctx, info.FullMethod, opts)
+		ctx, opts = withDebugLog(ctx, info.FullMethod, opts)
 		var (
 			reqObj zapcore.ObjectMarshaler
 		)
@@ -82,51 +130,182 @@ func UnaryInterceptor(options ...Option) grpc.UnaryServerInterceptor {
 				ctx = c
 			}
 		}
-		service, method := extractServiceAndMethod(info.FullMethod)
-		commonFields := buildCommonFields(service, method, info)
+		call := newCallInfo(ctx, info.FullMethod, start, opts)
+		if opts.debugBuffer != nil {
+			call.debugBuffer = newDebugBuffer(opts.debugBuffer.size)
+			ctx = logctx.WithLogger(ctx, bufferingLogger(logctx.From(ctx), call.debugBuffer))
+		}
+		if call.outgoing != nil {
+			ctx = captureOutgoingMetadata(ctx, call.outgoing)
+		}
+		if opts.requestID != nil {
+			ctx = withRequestID(ctx, opts.requestID, opts.naming)
+		}
+		if opts.traceContext {
+			ctx = withTraceContext(ctx, opts.naming)
+		}
+		ctx = context.WithValue(ctx, ctxKeyAddFields, call.fields)
 
-		ctx = logRequest(ctx, method, commonFields, reqObj, opts)
+		ctx = logRequest(ctx, call, reqObj, opts)
 		resp, err = handler(ctx, req)
 
 		var respObj zapcore.ObjectMarshaler
 		if opts.extractResponse != nil {
 			respObj = opts.extractResponse(ctx, resp)
 		}
-		logResponse(ctx, method, commonFields, reqObj, respObj, err, opts)
+		completeDebugBuffer(call, err, opts.debugBuffer)
+		logResponse(ctx, call, reqObj, respObj, err, opts)
 		return
 	}
 }
 
-func extractServiceAndMethod(fullMethod string) (string, string) {
-	toks := strings.Split(fullMethod[strings.LastIndexByte(fullMethod, '.')+1:], "/")
-	if len(toks) == 2 {
-		return toks[0], toks[1]
+// callInfo holds the information of a single call that is shared between the started and the completed log entries.
+type callInfo struct {
+	fullMethod   string
+	service      string
+	method       string
+	commonFields []zap.Field
+	logger       *zap.Logger
+	start        time.Time
+	deadline     time.Time
+	hasDeadline  bool
+	decision     Decision
+	incoming     metadata.MD
+	outgoing     *outgoingMetadata
+	fields       *fieldCollector
+	debugBuffer  *debugBuffer
+}
+
+func newCallInfo(ctx context.Context, fullMethod string, start time.Time, opts loggingOptions) *callInfo {
+	service, method := extractServiceAndMethod(fullMethod)
+	deadline, hasDeadline := ctx.Deadline()
+	commonFields := buildCommonFields(service, method, fullMethod)
+	commonFields = append(commonFields, opts.naming.fields...)
+	if opts.peerFields {
+		commonFields = append(commonFields, buildPeerFields(ctx)...)
+	}
+	call := &callInfo{
+		fullMethod:   fullMethod,
+		service:      service,
+		method:       method,
+		commonFields: commonFields,
+		logger:       logctx.From(ctx),
+		start:        start,
+		deadline:     deadline,
+		hasDeadline:  hasDeadline,
+		decision:     decide(opts.deciders, ctx, fullMethod, nil),
+		fields:       &fieldCollector{},
+	}
+	if opts.metadata != nil {
+		call.incoming, _ = metadata.FromIncomingContext(ctx)
+		if opts.metadata.outgoing {
+			call.outgoing = &outgoingMetadata{}
+		}
+	}
+	return call
+}
+
+// messageVars returns the values of the call available to the message templates.
+func (c *callInfo) messageVars() messageVars {
+	return messageVars{service: c.service, method: c.method, fullMethod: c.fullMethod}
+}
+
+// entryFields returns the fields every entry of the call starts with. When the common fields are carried by the logctx
+// logger of the call (see WithContextLogger), they are not repeated.
+func (c *callInfo) entryFields(opts loggingOptions) []zap.Field {
+	if opts.contextLogger {
+		return nil
+	}
+	return c.commonFields
+}
+
+// metadataFields returns the incoming metadata and, when the call has completed, the outgoing metadata.
+func (c *callInfo) metadataFields(opts loggingOptions, completed bool) []zap.Field {
+	if opts.metadata == nil {
+		return nil
+	}
+	var fields []zap.Field
+	if len(c.incoming) > 0 {
+		fields = append(fields, zap.Object(fieldGRPCMetadata, metadataMarshaler{c.incoming, opts.metadata}))
+	}
+	if completed && c.outgoing != nil {
+		fields = append(fields, c.outgoing.fields(opts.metadata)...)
+	}
+	return fields
+}
+
+// startLevel returns the level of the entries written before the call completes, and false when they should not be
+// written at all.
+func (c *callInfo) startLevel() (zapcore.Level, bool) {
+	switch c.decision {
+	case DecisionSkip, DecisionLogOnError:
+		return zapcore.InfoLevel, false
+	case DecisionDowngrade:
+		return zapcore.DebugLevel, true
+	default:
+		return zapcore.InfoLevel, true
+	}
+}
+
+// timingFields returns the start time, the duration and the deadline remaining at the start and at the end of the
+// call.
+func (c *callInfo) timingFields(end time.Time, opts loggingOptions) []zap.Field {
+	fields := make([]zap.Field, 0, 4)
+	fields = append(
+		fields,
+		zap.Time(fieldGRPCStartTime, c.start),
+		opts.durationField(fieldGRPCDuration, end.Sub(c.start)),
+	)
+	if c.hasDeadline {
+		fields = append(
+			fields,
+			opts.durationField(fieldGRPCDeadlineAtStart, c.deadline.Sub(c.start)),
+			opts.durationField(fieldGRPCDeadlineAtEnd, c.deadline.Sub(end)),
+		)
 	}
-	return "", ""
+	return fields
 }
 
-func logRequest(ctx context.Context, method string, fields []zap.Field, reqObj zapcore.ObjectMarshaler, opts loggingOptions) context.Context {
+func extractServiceAndMethod(fullMethod string) (string, string) {
+	return logfields.ExtractServiceAndMethod(fullMethod)
+}
+
+func logRequest(ctx context.Context, call *callInfo, reqObj zapcore.ObjectMarshaler, opts loggingOptions) context.Context {
+	if opts.contextLogger {
+		ctx = logctx.WithFields(ctx, opts.naming.rename(call.commonFields)...)
+		ctx = logfields.WithCallFields(ctx)
+	}
 	if !opts.logRequest {
 		return ctx
 	}
+	level, ok := call.startLevel()
+	if !ok {
+		return ctx
+	}
 
+	fields := call.entryFields(opts)
+	fields = append(fields, call.metadataFields(opts, false)...)
 	if reqObj != nil {
 		fields = append(fields, zap.Object(fieldGRPCRequest, reqObj))
 	}
 
-	logctx.Info(ctx, fmt.Sprintf(opts.requestMessage, method), fields...)
+	writeLog(ctx, level, opts.requestMessage.render(call.messageVars()), opts.naming.rename(fields)...)
 	return ctx
 }
 
-func logResponse(ctx context.Context, method string, fields []zap.Field, reqObj zapcore.ObjectMarshaler, respObj zapcore.ObjectMarshaler, err error, opts loggingOptions) {
-	if !opts.logResponse && err == nil {
+func logResponse(ctx context.Context, call *callInfo, reqObj zapcore.ObjectMarshaler, respObj zapcore.ObjectMarshaler, err error, opts loggingOptions, extraFields ...zap.Field) {
+	end := time.Now()
+	slowThreshold, slow := opts.slow.exceeded(call.fullMethod, end.Sub(call.start))
+	if !opts.logResponse && err == nil && !slow {
 		return
 	}
-
-	stCode := codes.OK
-	if s, ok := status.FromError(err); ok {
-		stCode = s.Code()
+	decision := decide(opts.deciders, ctx, call.fullMethod, err)
+	if decision == DecisionSkip || (decision == DecisionLogOnError && err == nil && !slow) {
+		return
 	}
+
+	fields := call.entryFields(opts)
+	stCode := logfields.StatusCode(err)
 	fields = append(
 		fields,
 		zap.String(fieldGRPCStatus, stCode.String()),
@@ -141,23 +320,76 @@ func logResponse(ctx context.Context, method string, fields []zap.Field, reqObj
 		fields = append(fields, zap.Object(fieldGRPCResponse, respObj))
 	}
 
-	writeLog := logctx.Info
+	fields = append(fields, extraFields...)
+	fields = append(fields, call.fields.get()...)
+	fields = append(fields, call.timingFields(end, opts)...)
+	fields = append(fields, call.metadataFields(opts, true)...)
+
 	logMessage := opts.responseMessage
 
 	if err != nil {
-		writeLog = logctx.Error
 		logMessage = opts.responseErrorMessage
-		if opts.handleError != nil {
-			fields = append(fields, opts.handleError(ctx, err)...)
+		fields = append(fields, errorFields(ctx, err, opts)...)
+	}
+
+	level := opts.levels.level(call.fullMethod, stCode)
+	if decision == DecisionDowngrade {
+		level = zapcore.DebugLevel
+	}
+	if slow {
+		fields = append(fields, opts.slow.fields(slowThreshold, opts)...)
+		if level < opts.slow.level {
+			level = opts.slow.level
+		}
+	}
+
+	if err != nil && opts.collapser != nil {
+		suppressed := opts.collapser.suppress(opts.collapser.opts.fingerprint(call.fullMethod, err), func() collapseSummary {
+			summaryFields := append(
+				call.commonFields[:len(call.commonFields):len(call.commonFields)],
+				zap.String(fieldGRPCStatus, stCode.String()),
+				zap.Uint32(fieldGRPCStatusCode, uint32(stCode)),
+			)
+			summaryFields = append(summaryFields, errorFields(ctx, err, opts)...)
+			return collapseSummary{
+				logger:  call.logger,
+				level:   level,
+				message: fmt.Sprintf(messageErrorsSuppressed, call.method),
+				fields:  summaryFields,
+				naming:  opts.naming,
+			}
+		})
+		if suppressed {
+			return
 		}
 	}
 
-	writeLog(ctx, fmt.Sprintf(logMessage, method), fields...)
+	vars := call.messageVars()
+	vars.code = stCode
+	vars.duration = end.Sub(call.start)
+	writeLog(ctx, level, logMessage.render(vars), opts.naming.rename(fields)...)
+}
+
+// errorFields renders err with the error handler, redacting its details first when a redactor is set.
+func errorFields(ctx context.Context, err error, opts loggingOptions) []zap.Field {
+	if opts.handleError == nil {
+		return nil
+	}
+	if opts.redactor != nil {
+		err = opts.redactor.redactError(err)
+	}
+	return opts.handleError(ctx, err)
+}
+
+func writeLog(ctx context.Context, level zapcore.Level, msg string, fields ...zap.Field) {
+	if ce := logctx.From(ctx).Check(level, msg); ce != nil {
+		ce.Write(fields...)
+	}
 }
 
-func buildCommonFields(service string, method string, info *grpc.UnaryServerInfo) []zap.Field {
+func buildCommonFields(service string, method string, fullMethod string) []zap.Field {
 	f := make([]zap.Field, 0, 4)
 	f = append(f, zap.String(fieldGRPCService, service)) // TODO Extract service name
 	f = append(f, zap.String(fieldGRPCMethod, method))
-	return append(f, zap.String(fieldGRPCFullMethod, info.FullMethod))
+	return append(f, zap.String(fieldGRPCFullMethod, fullMethod))
 }
@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/jamillosantos/logctx"
@@ -43,20 +44,22 @@ func TestInterceptor(t *testing.T) {
 		require.True(t, called, "the handler should be called")
 		entries := obs.All()
 		require.Len(t, entries, 2)
-		assert.Contains(t, " started", entries[0].Message)
+		assert.Contains(t, entries[0].Message, " started")
 		assert.Len(t, entries[0].Context, 4)
 		assert.Equal(t, entries[0].Context[0].Key, fieldGRPCService)
 		assert.Equal(t, entries[0].Context[1].Key, fieldGRPCMethod)
 		assert.Equal(t, entries[0].Context[2].Key, fieldGRPCFullMethod)
 		assert.Equal(t, entries[0].Context[3].Key, fieldGRPCRequest)
-		assert.Contains(t, " completed", entries[1].Message)
-		assert.Len(t, entries[1].Context, 6)
+		assert.Contains(t, entries[1].Message, " completed")
+		assert.Len(t, entries[1].Context, 8)
 		assert.Equal(t, entries[1].Context[0].Key, fieldGRPCService)
 		assert.Equal(t, entries[1].Context[1].Key, fieldGRPCMethod)
 		assert.Equal(t, entries[1].Context[2].Key, fieldGRPCFullMethod)
 		assert.Equal(t, entries[1].Context[3].Key, fieldGRPCStatus)
 		assert.Equal(t, entries[1].Context[4].Key, fieldGRPCStatusCode)
 		assert.Equal(t, entries[1].Context[5].Key, fieldGRPCResponse)
+		assert.Equal(t, entries[1].Context[6].Key, fieldGRPCStartTime)
+		assert.Equal(t, entries[1].Context[7].Key, fieldGRPCDuration)
 	})
 
 	t.Run("when operation start and completed are disabled", func(t *testing.T) {
@@ -100,23 +103,117 @@ func TestInterceptor(t *testing.T) {
 			entries := obs.All()
 			require.Len(t, entries, 1)
 
-			assert.Contains(t, " completed", entries[0].Message)
-			assert.Len(t, entries[0].Context, 6)
+			assert.Contains(t, entries[0].Message, " completed")
+			assert.Len(t, entries[0].Context, 9)
 			assert.Equal(t, entries[0].Context[0].Key, fieldGRPCService)
 			assert.Equal(t, entries[0].Context[1].Key, fieldGRPCMethod)
 			assert.Equal(t, entries[0].Context[2].Key, fieldGRPCFullMethod)
 			assert.Equal(t, entries[0].Context[3].Key, fieldGRPCStatus)
 			assert.Equal(t, entries[0].Context[4].Key, fieldGRPCStatusCode)
-			assert.Equal(t, entries[0].Context[5].Key, "error")
+			assert.Equal(t, entries[0].Context[5].Key, fieldGRPCStartTime)
+			assert.Equal(t, entries[0].Context[6].Key, fieldGRPCDuration)
+			assert.Equal(t, entries[0].Context[7].Key, "error")
+			assert.Equal(t, entries[0].Context[8].Key, fieldGRPCErrorKind)
 		})
 	})
 }
 
+func TestInterceptor_Timing(t *testing.T) {
+	t.Run("should log the duration and the remaining deadline", func(t *testing.T) {
+		ctx, obs := createObserver()
+		ctx, cancel := context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+		_, _ = UnaryInterceptor(
+			WithDurationField(DurationAsMilliseconds),
+		)(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			time.Sleep(time.Millisecond * 10)
+			return nil, nil
+		})
+		entries := obs.All()
+		require.Len(t, entries, 1)
+		fields := entries[0].ContextMap()
+		assert.IsType(t, time.Time{}, fields[fieldGRPCStartTime])
+		require.IsType(t, float64(0), fields[fieldGRPCDuration])
+		assert.GreaterOrEqual(t, fields[fieldGRPCDuration].(float64), float64(10))
+		require.IsType(t, float64(0), fields[fieldGRPCDeadlineAtStart])
+		require.IsType(t, float64(0), fields[fieldGRPCDeadlineAtEnd])
+		assert.Greater(t, fields[fieldGRPCDeadlineAtStart].(float64), fields[fieldGRPCDeadlineAtEnd].(float64))
+	})
+
+	t.Run("should not log the remaining deadline when the context has none", func(t *testing.T) {
+		ctx, obs := createObserver()
+		_, _ = UnaryInterceptor()(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		entries := obs.All()
+		require.Len(t, entries, 1)
+		fields := entries[0].ContextMap()
+		assert.Contains(t, fields, fieldGRPCDuration)
+		assert.NotContains(t, fields, fieldGRPCDeadlineAtStart)
+		assert.NotContains(t, fields, fieldGRPCDeadlineAtEnd)
+	})
+}
+
+func TestInterceptor_ContextLogger(t *testing.T) {
+	t.Run("should attribute the handler entries to the call", func(t *testing.T) {
+		ctx, obs := createObserver()
+		_, _ = UnaryInterceptor(WithOperationStarted(true))(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			logctx.Info(ctx, "handler log")
+			return nil, nil
+		})
+		entries := obs.All()
+		require.Len(t, entries, 3)
+		for _, entry := range entries {
+			count := 0
+			for _, f := range entry.Context {
+				if f.Key == fieldGRPCService {
+					count++
+				}
+			}
+			assert.Equal(t, 1, count, "the common fields should not be repeated")
+			fields := entry.ContextMap()
+			assert.Equal(t, "Service", fields[fieldGRPCService])
+			assert.Equal(t, "Method", fields[fieldGRPCMethod])
+			assert.Equal(t, "/pkg.Service/Method", fields[fieldGRPCFullMethod])
+		}
+	})
+
+	t.Run("should only add the common fields to the interceptor entries when disabled", func(t *testing.T) {
+		ctx, obs := createObserver()
+		_, _ = UnaryInterceptor(WithContextLogger(false))(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			logctx.Info(ctx, "handler log")
+			return nil, nil
+		})
+		entries := obs.All()
+		require.Len(t, entries, 2)
+		assert.NotContains(t, entries[0].ContextMap(), fieldGRPCService)
+		assert.Equal(t, "Service", entries[1].ContextMap()[fieldGRPCService])
+	})
+}
+
 func createObserver() (context.Context, *observer.ObservedLogs) {
 	zc, obs := observer.New(zapcore.DebugLevel)
 	return logctx.WithLogger(context.Background(), zap.New(zc)), obs
 }
 
+// callMethod calls "/pkg.Service/Method" through interceptor, with ctx carrying a logctx logger that writes the entries
+// at or above level to the returned observer.
+func callMethod(ctx context.Context, level zapcore.Level, interceptor grpc.UnaryServerInterceptor, req interface{}, handler grpc.UnaryHandler) *observer.ObservedLogs {
+	zc, obs := observer.New(level)
+	_, _ = interceptor(logctx.WithLogger(ctx, zap.New(zc)), req, &grpc.UnaryServerInfo{
+		FullMethod: "/pkg.Service/Method",
+	}, handler)
+	return obs
+}
+
 func createMockObjectMarshaler(ctrl *gomock.Controller) zapcore.ObjectMarshaler {
 	mock := NewMockObjectMarshaler(ctrl)
 	// mock.EXPECT().MarshalLogObject(gomock.Any()).Return(nil)
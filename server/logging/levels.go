@@ -0,0 +1,52 @@
+package logging
+
+import (
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc/codes"
+)
+
+// LevelFunc returns the level in which the completion of a call is logged, given its full method and status code.
+type LevelFunc func(fullMethod string, code codes.Code) zapcore.Level
+
+// DefaultCodeToLevel is the default mapping from status codes to log levels. Errors that are usually caused by the
+// client are logged as Info (or Warn, when they might be worth a look) while the ones that indicate a problem on the
+// server are logged as Error.
+func DefaultCodeToLevel(code codes.Code) zapcore.Level {
+	switch code {
+	case codes.OK, codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.Unauthenticated:
+		return zapcore.InfoLevel
+	case codes.DeadlineExceeded, codes.PermissionDenied, codes.ResourceExhausted, codes.FailedPrecondition,
+		codes.Aborted, codes.OutOfRange, codes.Unavailable:
+		return zapcore.WarnLevel
+	case codes.Unknown, codes.Unimplemented, codes.Internal, codes.DataLoss:
+		return zapcore.ErrorLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
+
+// levels resolves the level of the completion log entry. Per method overrides take precedence over the per code
+// overrides, which take precedence over levelFunc.
+type levels struct {
+	levelFunc   LevelFunc
+	codes       map[codes.Code]zapcore.Level
+	methodCodes map[string]map[codes.Code]zapcore.Level
+}
+
+func defaultLevels() levels {
+	return levels{
+		levelFunc: func(_ string, code codes.Code) zapcore.Level {
+			return DefaultCodeToLevel(code)
+		},
+	}
+}
+
+func (l levels) level(fullMethod string, code codes.Code) zapcore.Level {
+	if lvl, ok := l.methodCodes[fullMethod][code]; ok {
+		return lvl
+	}
+	if lvl, ok := l.codes[code]; ok {
+		return lvl
+	}
+	return l.levelFunc(fullMethod, code)
+}
@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDefaultCodeToLevel(t *testing.T) {
+	assert.Equal(t, zapcore.InfoLevel, DefaultCodeToLevel(codes.OK))
+	assert.Equal(t, zapcore.InfoLevel, DefaultCodeToLevel(codes.NotFound))
+	assert.Equal(t, zapcore.InfoLevel, DefaultCodeToLevel(codes.InvalidArgument))
+	assert.Equal(t, zapcore.WarnLevel, DefaultCodeToLevel(codes.DeadlineExceeded))
+	assert.Equal(t, zapcore.ErrorLevel, DefaultCodeToLevel(codes.Internal))
+	assert.Equal(t, zapcore.ErrorLevel, DefaultCodeToLevel(codes.Unknown))
+}
+
+func TestLevels(t *testing.T) {
+	var opts loggingOptions
+	opts.levels = defaultLevels()
+	WithCodeLevel(codes.NotFound, zapcore.DebugLevel)(&opts)
+	WithMethodCodeLevel("/pkg.Service/Method", codes.NotFound, zapcore.ErrorLevel)(&opts)
+
+	assert.Equal(t, zapcore.ErrorLevel, opts.levels.level("/pkg.Service/Method", codes.NotFound))
+	assert.Equal(t, zapcore.DebugLevel, opts.levels.level("/pkg.Service/Other", codes.NotFound))
+	assert.Equal(t, zapcore.ErrorLevel, opts.levels.level("/pkg.Service/Other", codes.Internal))
+
+	WithLevelFunc(func(string, codes.Code) zapcore.Level { return zapcore.WarnLevel })(&opts)
+	assert.Equal(t, zapcore.WarnLevel, opts.levels.level("/pkg.Service/Other", codes.Internal))
+}
+
+func TestInterceptor_Levels(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		options   []Option
+		wantLevel zapcore.Level
+	}{
+		{"client error", status.Error(codes.NotFound, "not found"), nil, zapcore.InfoLevel},
+		{"server error", status.Error(codes.Internal, "internal"), nil, zapcore.ErrorLevel},
+		{"plain error", errors.New("failed"), nil, zapcore.ErrorLevel},
+		{"overridden code", status.Error(codes.Internal, "internal"), []Option{WithCodeLevel(codes.Internal, zapcore.WarnLevel)}, zapcore.WarnLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, obs := createObserver()
+			_, _ = UnaryInterceptor(tt.options...)(ctx, nil, &grpc.UnaryServerInfo{
+				FullMethod: "/pkg.Service/Method",
+			}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, tt.err
+			})
+			entries := obs.All()
+			require.Len(t, entries, 1)
+			assert.Equal(t, tt.wantLevel, entries[0].Level)
+		})
+	}
+}
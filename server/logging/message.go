@@ -0,0 +1,130 @@
+package logging
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// messagePlaceholder is a value that can be rendered in a message template.
+type messagePlaceholder int
+
+const (
+	placeholderNone messagePlaceholder = iota
+	placeholderService
+	placeholderMethod
+	placeholderFullMethod
+	placeholderCode
+	placeholderDuration
+)
+
+var messagePlaceholders = map[string]messagePlaceholder{
+	"service":     placeholderService,
+	"method":      placeholderMethod,
+	"full_method": placeholderFullMethod,
+	"code":        placeholderCode,
+	"duration":    placeholderDuration,
+}
+
+// messageVars are the values available to the message templates. code and duration are only known when the call
+// completes.
+type messageVars struct {
+	service    string
+	method     string
+	fullMethod string
+	code       codes.Code
+	duration   time.Duration
+}
+
+type messagePart struct {
+	literal     string
+	placeholder messagePlaceholder
+}
+
+// messageTemplate is a parsed log message template. See WithResponseMessage for its syntax.
+type messageTemplate struct {
+	parts []messagePart
+}
+
+// parseMessageTemplate parses tmpl. When completed is false, the template is rendered before the call completes, so
+// the {code} and {duration} placeholders are rejected.
+func parseMessageTemplate(tmpl string, completed bool) (*messageTemplate, error) {
+	t := &messageTemplate{}
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			t.parts = append(t.parts, messagePart{literal: literal.String()})
+			literal.Reset()
+		}
+	}
+	legacy := false
+	for i := 0; i < len(tmpl); i++ {
+		switch c := tmpl[i]; {
+		case strings.HasPrefix(tmpl[i:], "{{"), strings.HasPrefix(tmpl[i:], "}}"):
+			literal.WriteByte(c)
+			i++
+		case strings.HasPrefix(tmpl[i:], "%s"):
+			// Templates used to be fmt formats receiving the method name, which is still supported.
+			if legacy {
+				return nil, fmt.Errorf("invalid message template %q: %%s can be used only once", tmpl)
+			}
+			legacy = true
+			flush()
+			t.parts = append(t.parts, messagePart{placeholder: placeholderMethod})
+			i++
+		case c == '{':
+			end := strings.IndexByte(tmpl[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid message template %q: unclosed placeholder", tmpl)
+			}
+			name := tmpl[i+1 : i+end]
+			placeholder, ok := messagePlaceholders[name]
+			if !ok {
+				return nil, fmt.Errorf("invalid message template %q: unknown placeholder {%s}", tmpl, name)
+			}
+			if !completed && (placeholder == placeholderCode || placeholder == placeholderDuration) {
+				return nil, fmt.Errorf("invalid message template %q: {%s} is only available when the call completes", tmpl, name)
+			}
+			flush()
+			t.parts = append(t.parts, messagePart{placeholder: placeholder})
+			i += end
+		case c == '}':
+			return nil, fmt.Errorf("invalid message template %q: unexpected }", tmpl)
+		default:
+			literal.WriteByte(c)
+		}
+	}
+	flush()
+	return t, nil
+}
+
+func mustParseMessageTemplate(tmpl string, completed bool) *messageTemplate {
+	t, err := parseMessageTemplate(tmpl, completed)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func (t *messageTemplate) render(vars messageVars) string {
+	var sb strings.Builder
+	for _, part := range t.parts {
+		switch part.placeholder {
+		case placeholderNone:
+			sb.WriteString(part.literal)
+		case placeholderService:
+			sb.WriteString(vars.service)
+		case placeholderMethod:
+			sb.WriteString(vars.method)
+		case placeholderFullMethod:
+			sb.WriteString(vars.fullMethod)
+		case placeholderCode:
+			sb.WriteString(vars.code.String())
+		case placeholderDuration:
+			sb.WriteString(vars.duration.String())
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseMessageTemplate(t *testing.T) {
+	vars := messageVars{
+		service:    "Service",
+		method:     "Method",
+		fullMethod: "/pkg.Service/Method",
+		code:       codes.NotFound,
+		duration:   time.Second,
+	}
+
+	for tmpl, want := range map[string]string{
+		"%s completed":                                "Method completed",
+		"{service}.{method} completed":                "Service.Method completed",
+		"{full_method} returned {code} in {duration}": "/pkg.Service/Method returned NotFound in 1s",
+		"{{method}} 100%":                             "{method} 100%",
+		"":                                            "",
+	} {
+		got, err := parseMessageTemplate(tmpl, true)
+		require.NoError(t, err, tmpl)
+		assert.Equal(t, want, got.render(vars), tmpl)
+	}
+
+	for _, tmpl := range []string{"{unknown}", "{method", "method}", "%s %s"} {
+		_, err := parseMessageTemplate(tmpl, true)
+		assert.Error(t, err, tmpl)
+	}
+
+	_, err := parseMessageTemplate("{method} took {duration}", false)
+	assert.Error(t, err, "the duration should not be available before the call completes")
+}
+
+func TestWithMessages(t *testing.T) {
+	assert.Panics(t, func() { WithRequestMessage("{code}") })
+	assert.Panics(t, func() { WithResponseMessage("{unknown}") })
+
+	ctx, obs := createObserver()
+	interceptor := UnaryInterceptor(
+		WithOperationStarted(true),
+		WithRequestMessage("{full_method} started"),
+		WithResponseMessage("{service}/{method} returned {code}"),
+		WithResponseErrorMessage("{method} failed with {code}"),
+	)
+	for _, wantErr := range []error{nil, status.Error(codes.NotFound, "not found")} {
+		_, _ = interceptor(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, wantErr
+		})
+	}
+	entries := obs.All()
+	require.Len(t, entries, 4)
+	assert.Equal(t, "/pkg.Service/Method started", entries[0].Message)
+	assert.Equal(t, "Service/Method returned OK", entries[1].Message)
+	assert.Equal(t, "Method failed with NotFound", entries[3].Message)
+}
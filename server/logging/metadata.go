@@ -0,0 +1,220 @@
+package logging
+
+import (
+	"context"
+	"encoding/base64"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	fieldGRPCMetadata        = "grpc.metadata"
+	fieldGRPCMetadataHeader  = "grpc.metadata.header"
+	fieldGRPCMetadataTrailer = "grpc.metadata.trailer"
+)
+
+// defaultMetadataDenylist are the keys, usually carrying credentials, that are never logged, unless they are redacted
+// (see MetadataRedact).
+var defaultMetadataDenylist = []string{
+	"authorization", "proxy-authorization", "cookie", "set-cookie",
+	"x-api-key", "api-key", "x-auth-token", "x-access-token", "x-refresh-token", "x-csrf-token", "x-xsrf-token",
+	"x-amz-security-token", "x-goog-iam-authorization-token",
+}
+
+type metadataOptions struct {
+	allow    map[string]struct{}
+	allowAll bool
+	deny     map[string]struct{}
+	redact   map[string]struct{}
+	outgoing bool
+}
+
+// MetadataOption configures which metadata keys are logged by WithMetadata.
+type MetadataOption func(*metadataOptions)
+
+func defaultMetadataOptions() *metadataOptions {
+	opts := &metadataOptions{
+		allow:  make(map[string]struct{}),
+		deny:   make(map[string]struct{}),
+		redact: make(map[string]struct{}),
+	}
+	addMetadataKeys(opts.deny, defaultMetadataDenylist)
+	return opts
+}
+
+func addMetadataKeys(set map[string]struct{}, keys []string) {
+	for _, k := range keys {
+		set[strings.ToLower(k)] = struct{}{}
+	}
+}
+
+// MetadataAllow logs the given keys. No key is logged until it is allowed, either by MetadataAllow or MetadataAllowAll.
+func MetadataAllow(keys ...string) MetadataOption {
+	return func(opts *metadataOptions) {
+		addMetadataKeys(opts.allow, keys)
+	}
+}
+
+// MetadataAllowAll logs every key that is not denied. As any header carrying credentials that is not in the default
+// denylist (see MetadataDeny) would be logged, prefer MetadataAllow.
+func MetadataAllowAll() MetadataOption {
+	return func(opts *metadataOptions) {
+		opts.allowAll = true
+	}
+}
+
+// MetadataDeny prevents the given keys from being logged. The usual credential headers ("authorization",
+// "proxy-authorization", "cookie", "set-cookie", "x-api-key", "api-key", "x-auth-token", "x-access-token",
+// "x-refresh-token", "x-csrf-token", "x-xsrf-token", "x-amz-security-token" and "x-goog-iam-authorization-token") are
+// always denied.
+func MetadataDeny(keys ...string) MetadataOption {
+	return func(opts *metadataOptions) {
+		addMetadataKeys(opts.deny, keys)
+	}
+}
+
+// MetadataRedact logs the given keys with their values replaced by "[REDACTED]", so their presence is still visible.
+// Redacted keys are logged even when they are denied.
+func MetadataRedact(keys ...string) MetadataOption {
+	return func(opts *metadataOptions) {
+		addMetadataKeys(opts.redact, keys)
+	}
+}
+
+// MetadataOutgoing enables, or disables, logging the headers and trailers set by the handler, under the
+// "grpc.metadata.header" and "grpc.metadata.trailer" fields.
+func MetadataOutgoing(enable bool) MetadataOption {
+	return func(opts *metadataOptions) {
+		opts.outgoing = enable
+	}
+}
+
+// metadataMarshaler renders the keys of md that are allowed by opts.
+type metadataMarshaler struct {
+	md   metadata.MD
+	opts *metadataOptions
+}
+
+func (m metadataMarshaler) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	keys := make([]string, 0, len(m.md))
+	for k := range m.md {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if _, ok := m.opts.redact[k]; ok {
+			encoder.AddString(k, redactedValue)
+			continue
+		}
+		if _, ok := m.opts.deny[k]; ok {
+			continue
+		}
+		if _, ok := m.opts.allow[k]; !m.opts.allowAll && !ok {
+			continue
+		}
+		values := m.md[k]
+		if strings.HasSuffix(k, "-bin") {
+			encoded := make([]string, len(values))
+			for i, v := range values {
+				encoded[i] = base64.StdEncoding.EncodeToString([]byte(v))
+			}
+			values = encoded
+		}
+		if len(values) == 1 {
+			encoder.AddString(k, values[0])
+			continue
+		}
+		if err := encoder.AddArray(k, zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			for _, v := range values {
+				arr.AppendString(v)
+			}
+			return nil
+		})); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outgoingMetadata collects the headers and trailers set by the handler.
+type outgoingMetadata struct {
+	mu      sync.Mutex
+	header  metadata.MD
+	trailer metadata.MD
+}
+
+func (o *outgoingMetadata) addHeader(md metadata.MD) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.header = metadata.Join(o.header, md)
+}
+
+func (o *outgoingMetadata) addTrailer(md metadata.MD) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.trailer = metadata.Join(o.trailer, md)
+}
+
+func (o *outgoingMetadata) fields(opts *metadataOptions) []zap.Field {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	fields := make([]zap.Field, 0, 2)
+	if len(o.header) > 0 {
+		fields = append(fields, zap.Object(fieldGRPCMetadataHeader, metadataMarshaler{o.header, opts}))
+	}
+	if len(o.trailer) > 0 {
+		fields = append(fields, zap.Object(fieldGRPCMetadataTrailer, metadataMarshaler{o.trailer, opts}))
+	}
+	return fields
+}
+
+// metadataCapturingTransportStream wraps the grpc.ServerTransportStream of the call, so the metadata set through
+// grpc.SetHeader, grpc.SendHeader and grpc.SetTrailer is collected.
+type metadataCapturingTransportStream struct {
+	grpc.ServerTransportStream
+	outgoing *outgoingMetadata
+}
+
+func (s *metadataCapturingTransportStream) SetHeader(md metadata.MD) error {
+	err := s.ServerTransportStream.SetHeader(md)
+	if err == nil {
+		s.outgoing.addHeader(md)
+	}
+	return err
+}
+
+func (s *metadataCapturingTransportStream) SendHeader(md metadata.MD) error {
+	err := s.ServerTransportStream.SendHeader(md)
+	if err == nil {
+		s.outgoing.addHeader(md)
+	}
+	return err
+}
+
+func (s *metadataCapturingTransportStream) SetTrailer(md metadata.MD) error {
+	err := s.ServerTransportStream.SetTrailer(md)
+	if err == nil {
+		s.outgoing.addTrailer(md)
+	}
+	return err
+}
+
+// captureOutgoingMetadata replaces the grpc.ServerTransportStream of ctx by one that collects the outgoing metadata
+// into outgoing.
+func captureOutgoingMetadata(ctx context.Context, outgoing *outgoingMetadata) context.Context {
+	sts := grpc.ServerTransportStreamFromContext(ctx)
+	if sts == nil {
+		return ctx
+	}
+	return grpc.NewContextWithServerTransportStream(ctx, &metadataCapturingTransportStream{
+		ServerTransportStream: sts,
+		outgoing:              outgoing,
+	})
+}
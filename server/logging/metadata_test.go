@@ -0,0 +1,109 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type fakeServerTransportStream struct {
+	grpc.ServerTransportStream
+	header  metadata.MD
+	trailer metadata.MD
+}
+
+func (f *fakeServerTransportStream) SetHeader(md metadata.MD) error {
+	f.header = metadata.Join(f.header, md)
+	return nil
+}
+
+func (f *fakeServerTransportStream) SendHeader(md metadata.MD) error {
+	return f.SetHeader(md)
+}
+
+func (f *fakeServerTransportStream) SetTrailer(md metadata.MD) error {
+	f.trailer = metadata.Join(f.trailer, md)
+	return nil
+}
+
+func TestInterceptor_Metadata(t *testing.T) {
+	incoming := metadata.Pairs(
+		"user-agent", "grpc-go/1.50.1",
+		"x-tenant", "acme",
+		"x-client-version", "1.0",
+		"x-client-version", "1.1",
+		"authorization", "Bearer token",
+		"x-api-key", "key",
+		"x-trace-bin", "\x01\x02",
+	)
+
+	tests := []struct {
+		name    string
+		options []MetadataOption
+		want    map[string]interface{}
+	}{
+		{
+			name: "should not log any key by default",
+			want: map[string]interface{}{},
+		},
+		{
+			name:    "should log every key except the denied ones",
+			options: []MetadataOption{MetadataAllowAll()},
+			want: map[string]interface{}{
+				"user-agent":       "grpc-go/1.50.1",
+				"x-tenant":         "acme",
+				"x-client-version": []interface{}{"1.0", "1.1"},
+				"x-trace-bin":      "AQI=",
+			},
+		},
+		{
+			name:    "should only log the allowed keys",
+			options: []MetadataOption{MetadataAllow("X-Tenant", "authorization")},
+			want:    map[string]interface{}{"x-tenant": "acme"},
+		},
+		{
+			name:    "should redact and deny keys",
+			options: []MetadataOption{MetadataAllow("x-tenant", "x-api-key", "authorization"), MetadataDeny("x-tenant"), MetadataRedact("x-api-key", "authorization")},
+			want:    map[string]interface{}{"x-api-key": redactedValue, "authorization": redactedValue},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, obs := createObserver()
+			ctx = metadata.NewIncomingContext(ctx, incoming)
+			_, _ = UnaryInterceptor(WithMetadata(tt.options...))(ctx, nil, &grpc.UnaryServerInfo{
+				FullMethod: "/pkg.Service/Method",
+			}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, nil
+			})
+			entries := obs.All()
+			require.Len(t, entries, 1)
+			assert.Equal(t, tt.want, entries[0].ContextMap()[fieldGRPCMetadata])
+		})
+	}
+
+	t.Run("should log the headers and trailers set by the handler", func(t *testing.T) {
+		sts := &fakeServerTransportStream{}
+		ctx, obs := createObserver()
+		ctx = grpc.NewContextWithServerTransportStream(ctx, sts)
+		_, _ = UnaryInterceptor(WithMetadata(MetadataAllowAll(), MetadataOutgoing(true)))(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			require.NoError(t, grpc.SetHeader(ctx, metadata.Pairs("x-request-id", "123", "set-cookie", "session")))
+			require.NoError(t, grpc.SetTrailer(ctx, metadata.Pairs("x-cost", "10")))
+			return nil, nil
+		})
+		assert.Equal(t, []string{"123"}, sts.header.Get("x-request-id"), "the header should reach the transport stream")
+
+		entries := obs.All()
+		require.Len(t, entries, 1)
+		fields := entries[0].ContextMap()
+		assert.NotContains(t, fields, fieldGRPCMetadata)
+		assert.Equal(t, map[string]interface{}{"x-request-id": "123"}, fields[fieldGRPCMetadataHeader])
+		assert.Equal(t, map[string]interface{}{"x-cost": "10"}, fields[fieldGRPCMetadataTrailer])
+	})
+}
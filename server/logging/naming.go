@@ -0,0 +1,93 @@
+package logging
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/jamillosantos/go-grpc-interceptors/internal/tracecontext"
+)
+
+// NamingScheme renames the keys of the fields written by the interceptors, so they match the schema indexed by the
+// log platform. Only top level keys are renamed, the keys inside objects (such as the request, the response or the
+// error details) are kept. See NamingOpenTelemetry, NamingECS and NamingCustom.
+type NamingScheme struct {
+	keys   map[string]string
+	fields []zap.Field
+}
+
+// NamingOpenTelemetry follows the OpenTelemetry semantic conventions for RPC: "rpc.system" is set to "grpc", the
+// service, method and status code are logged as "rpc.service", "rpc.method" and "rpc.grpc.status_code", the peer as
+// "network.peer.address" and "tls.cipher" and the status message as "exception.message".
+func NamingOpenTelemetry() NamingScheme {
+	return NamingScheme{
+		keys: map[string]string{
+			fieldGRPCService:       "rpc.service",
+			fieldGRPCMethod:        "rpc.method",
+			fieldGRPCStatusCode:    "rpc.grpc.status_code",
+			fieldGRPCPeerAddress:   "network.peer.address",
+			fieldGRPCPeerTLSCipher: "tls.cipher",
+			fieldGRPCErrorMessage:  "exception.message",
+		},
+		fields: []zap.Field{zap.String("rpc.system", "grpc")},
+	}
+}
+
+// NamingECS follows the Elastic Common Schema: the error is logged as "error.message", the trace context as "trace.id",
+// "span.id" and "parent.id", the start time and the duration as "event.start" and "event.duration" and the peer as
+// "client.address" and "tls.*". ECS expects "event.duration" in nanoseconds, so it should be used together with
+// WithDurationField(DurationAsNanoseconds).
+func NamingECS() NamingScheme {
+	return NamingScheme{
+		keys: map[string]string{
+			"error":                        "error.message",
+			fieldGRPCStartTime:             "event.start",
+			fieldGRPCDuration:              "event.duration",
+			fieldGRPCPeerAddress:           "client.address",
+			fieldGRPCPeerTLSCipher:         "tls.cipher",
+			fieldGRPCPeerTLSSubject:        "tls.client.subject",
+			tracecontext.FieldTraceID:      "trace.id",
+			tracecontext.FieldSpanID:       "span.id",
+			tracecontext.FieldParentSpanID: "parent.id",
+		},
+	}
+}
+
+// NamingCustom renames the keys found in keys (eg: "grpc.status_code" to "status") and keeps the others.
+func NamingCustom(keys map[string]string) NamingScheme {
+	return NamingScheme{}.With(keys)
+}
+
+// With returns a copy of the scheme with the given keys renamed as well, overriding the renames of the scheme (eg:
+// NamingOpenTelemetry().With(map[string]string{"grpc.request_id": "request.id"})).
+func (s NamingScheme) With(keys map[string]string) NamingScheme {
+	merged := make(map[string]string, len(s.keys)+len(keys))
+	for k, v := range s.keys {
+		merged[k] = v
+	}
+	for k, v := range keys {
+		merged[k] = v
+	}
+	return NamingScheme{keys: merged, fields: s.fields}
+}
+
+// rename returns fields with their keys renamed. fields is not changed, as it can be shared between entries.
+func (s NamingScheme) rename(fields []zap.Field) []zap.Field {
+	if len(s.keys) == 0 {
+		return fields
+	}
+	var renamed []zap.Field
+	for i, f := range fields {
+		key, ok := s.keys[f.Key]
+		if !ok {
+			continue
+		}
+		if renamed == nil {
+			renamed = make([]zap.Field, len(fields))
+			copy(renamed, fields)
+		}
+		renamed[i].Key = key
+	}
+	if renamed == nil {
+		return fields
+	}
+	return renamed
+}
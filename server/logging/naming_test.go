@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jamillosantos/logctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNamingScheme(t *testing.T) {
+	t.Run("should use the OpenTelemetry semantic conventions", func(t *testing.T) {
+		ctx, obs := createObserver()
+		_, _ = UnaryInterceptor(WithNamingScheme(NamingOpenTelemetry()))(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			logctx.Info(ctx, "handler log")
+			return nil, status.Error(codes.NotFound, "not found")
+		})
+		entries := obs.All()
+		require.Len(t, entries, 2)
+		for _, entry := range entries {
+			fields := entry.ContextMap()
+			assert.Equal(t, "grpc", fields["rpc.system"])
+			assert.Equal(t, "Service", fields["rpc.service"])
+			assert.Equal(t, "Method", fields["rpc.method"])
+			assert.NotContains(t, fields, fieldGRPCService)
+		}
+		fields := entries[1].ContextMap()
+		assert.Equal(t, uint32(codes.NotFound), fields["rpc.grpc.status_code"])
+		assert.Equal(t, "not found", fields["exception.message"])
+		assert.NotContains(t, fields, fieldGRPCStatusCode)
+	})
+
+	t.Run("should use the Elastic Common Schema", func(t *testing.T) {
+		ctx, obs := createObserver()
+		_, _ = UnaryInterceptor(
+			WithNamingScheme(NamingECS()),
+			WithDurationField(DurationAsNanoseconds),
+			WithTraceContext(true),
+		)(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(codes.Internal, "failure")
+		})
+		entries := obs.All()
+		require.Len(t, entries, 1)
+		fields := entries[0].ContextMap()
+		assert.Contains(t, fields, "trace.id")
+		assert.Contains(t, fields, "span.id")
+		assert.Contains(t, fields, "event.start")
+		assert.IsType(t, int64(0), fields["event.duration"])
+		assert.Equal(t, "rpc error: code = Internal desc = failure", fields["error.message"])
+		assert.NotContains(t, fields, "error")
+	})
+
+	t.Run("should rename custom keys on top of a preset", func(t *testing.T) {
+		ctx, obs := createObserver()
+		_, _ = UnaryInterceptor(
+			WithNamingScheme(NamingOpenTelemetry().With(map[string]string{fieldGRPCStatus: "rpc.grpc.status", fieldGRPCMethod: "method"})),
+			WithRequestID(RequestIDGenerator(func() string { return "id" }), RequestIDEcho(false)),
+		)(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		fields := obs.All()[0].ContextMap()
+		assert.Equal(t, "OK", fields["rpc.grpc.status"])
+		assert.Equal(t, "Method", fields["method"])
+		assert.Equal(t, "Service", fields["rpc.service"])
+		assert.Equal(t, "id", fields[fieldGRPCRequestID])
+	})
+
+	t.Run("should not change the given fields", func(t *testing.T) {
+		fields := []zap.Field{zap.String(fieldGRPCService, "Service")}
+		renamed := NamingCustom(map[string]string{fieldGRPCService: "service"}).rename(fields)
+		assert.Equal(t, "service", renamed[0].Key)
+		assert.Equal(t, fieldGRPCService, fields[0].Key)
+	})
+}
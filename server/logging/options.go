@@ -2,8 +2,11 @@ package logging
 
 import (
 	"context"
+	"time"
 
+	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc/codes"
 )
 
 func WithOperationStarted(enable bool) Option {
@@ -35,3 +38,269 @@ func WithErrorHandler(handler func(ctx context.Context, err error) []zapcore.Fie
 		opts.handleError = handler
 	}
 }
+
+// WithDurationField sets how the duration of the call and the remaining deadline are encoded. See DurationAsDuration,
+// DurationAsMilliseconds, DurationAsSeconds and DurationAsNanoseconds.
+func WithDurationField(durationField DurationField) Option {
+	return func(opts *loggingOptions) {
+		opts.durationField = durationField
+	}
+}
+
+// WithLevelFunc replaces the function that maps the status code of a call to the level of its completion log entry.
+// The default is DefaultCodeToLevel.
+func WithLevelFunc(levelFunc LevelFunc) Option {
+	return func(opts *loggingOptions) {
+		opts.levels.levelFunc = levelFunc
+	}
+}
+
+// WithCodeLevel overrides the level used to log the completion of calls that end with the given code.
+func WithCodeLevel(code codes.Code, level zapcore.Level) Option {
+	return func(opts *loggingOptions) {
+		if opts.levels.codes == nil {
+			opts.levels.codes = make(map[codes.Code]zapcore.Level)
+		}
+		opts.levels.codes[code] = level
+	}
+}
+
+// WithMethodCodeLevel overrides the level used to log the completion of calls to fullMethod (eg:
+// "/package.Service/Method") that end with the given code.
+func WithMethodCodeLevel(fullMethod string, code codes.Code, level zapcore.Level) Option {
+	return func(opts *loggingOptions) {
+		if opts.levels.methodCodes == nil {
+			opts.levels.methodCodes = make(map[string]map[codes.Code]zapcore.Level)
+		}
+		if opts.levels.methodCodes[fullMethod] == nil {
+			opts.levels.methodCodes[fullMethod] = make(map[codes.Code]zapcore.Level)
+		}
+		opts.levels.methodCodes[fullMethod][code] = level
+	}
+}
+
+// WithDecider adds a Decider that can skip, downgrade or restrict to failures the logs of a call. When multiple
+// deciders are added, the first decision other than DecisionLog is used.
+func WithDecider(decider Decider) Option {
+	return func(opts *loggingOptions) {
+		opts.deciders = append(opts.deciders, decider)
+	}
+}
+
+// WithMethodDecision applies decision to the methods matched by matcher. See MatchMethods, MatchGlob and MatchRegexp.
+func WithMethodDecision(matcher MethodMatcher, decision Decision) Option {
+	return WithDecider(MethodDecider(matcher, decision))
+}
+
+// WithRedactor redacts the sensitive fields of the status details before they reach the error handler, and of the
+// payloads logged by WithProtoPayloads. To redact the payloads of the proto extractors, use ProtoRedactor.
+func WithRedactor(redactor *Redactor) Option {
+	return func(opts *loggingOptions) {
+		opts.redactor = redactor
+	}
+}
+
+// WithProtoPayloads logs the request and the response, when they are proto messages, using ProtoRequestExtractor and
+// ProtoResponseExtractor with the given options. The payloads are redacted with the redactor set by WithRedactor or,
+// when there is none, the fields annotated with `debug_redact` are. A ProtoRedactor in options takes precedence.
+func WithProtoPayloads(options ...ProtoOption) Option {
+	return func(opts *loggingOptions) {
+		// The extractors are only created once all the options are applied, so WithRedactor can come after this.
+		opts.protoPayloads = append([]ProtoOption{}, options...)
+		opts.extractRequest = nil
+		opts.extractResponse = nil
+	}
+}
+
+// WithPeerFields enables, or disables, logging the peer of the call: its remote address, the transport security type
+// and, for TLS connections, the version, the cipher suite and the subject and SANs of the verified client certificate.
+func WithPeerFields(enable bool) Option {
+	return func(opts *loggingOptions) {
+		opts.peerFields = enable
+	}
+}
+
+// WithMetadata logs the incoming metadata of the call under the "grpc.metadata" field. No key is logged until it is
+// selected with MetadataAllow (or MetadataAllowAll, which logs every key except the usual credential headers, so other
+// headers carrying secrets would be logged). Use MetadataDeny and MetadataRedact to hide keys, and MetadataOutgoing to
+// also log the headers and trailers set by the handler.
+func WithMetadata(options ...MetadataOption) Option {
+	return func(opts *loggingOptions) {
+		opts.metadata = defaultMetadataOptions()
+		for _, opt := range options {
+			opt(opts.metadata)
+		}
+	}
+}
+
+// WithRequestID reads the request ID of the call from the incoming metadata ("x-request-id" by default), generating
+// one when it is missing. The ID is added to every entry written through the logctx logger of the call, echoed in the
+// response headers and can be retrieved with RequestIDFromContext.
+func WithRequestID(options ...RequestIDOption) Option {
+	return func(opts *loggingOptions) {
+		opts.requestID = defaultRequestIDOptions()
+		for _, opt := range options {
+			opt(opts.requestID)
+		}
+	}
+}
+
+// WithTraceContext enables, or disables, the W3C Trace Context propagation. The trace context of the call is read from
+// the incoming `traceparent` and `tracestate` metadata (a new trace is started when it is missing or malformed) and a
+// new span ID is generated for the call. The trace, span and parent span IDs are added to every entry written through
+// the logctx logger of the call, and the trace context is propagated by the client/logging interceptors to the calls
+// made by the handler.
+func WithTraceContext(enable bool) Option {
+	return func(opts *loggingOptions) {
+		opts.traceContext = enable
+	}
+}
+
+// WithContextLogger enables, or disables, installing a logctx logger carrying the common fields of the call (service,
+// method and, when enabled, peer fields) in the context given to the handler, so every entry written by the handler
+// is attributed to the call. It is enabled by default. When disabled, the common fields are only added to the
+// entries written by the interceptor.
+func WithContextLogger(enable bool) Option {
+	return func(opts *loggingOptions) {
+		opts.contextLogger = enable
+	}
+}
+
+// WithLogger writes the entries of the interceptor to logger, instead of the logctx logger of the context. The logger
+// is also installed in the context given to the handler, so the entries written through logctx go to it as well.
+func WithLogger(logger *zap.Logger) Option {
+	return func(opts *loggingOptions) {
+		opts.logger = logger
+	}
+}
+
+// WithNamingScheme renames the keys of the fields written by the interceptor, including the ones added to the logctx
+// logger of the call. See NamingOpenTelemetry, NamingECS and NamingCustom.
+func WithNamingScheme(scheme NamingScheme) Option {
+	return func(opts *loggingOptions) {
+		opts.naming = scheme
+	}
+}
+
+// WithRequestMessage sets the message of the entry written when the call starts. See WithResponseMessage for the
+// syntax of the template, except that {code} and {duration} are not available. It panics if the template is invalid.
+func WithRequestMessage(template string) Option {
+	t := mustParseMessageTemplate(template, false)
+	return func(opts *loggingOptions) {
+		opts.requestMessage = t
+	}
+}
+
+// WithResponseMessage sets the message of the entry written when the call completes successfully. The template can
+// use the {service}, {method}, {full_method}, {code} and {duration} placeholders (eg: "{full_method} returned {code}
+// in {duration}"), "{{" and "}}" for literal braces and, for backwards compatibility, a single "%s" for the method. It
+// panics if the template is invalid.
+func WithResponseMessage(template string) Option {
+	t := mustParseMessageTemplate(template, true)
+	return func(opts *loggingOptions) {
+		opts.responseMessage = t
+	}
+}
+
+// WithResponseErrorMessage sets the message of the entry written when the call fails. See WithResponseMessage for the
+// syntax of the template. It panics if the template is invalid.
+func WithResponseErrorMessage(template string) Option {
+	t := mustParseMessageTemplate(template, true)
+	return func(opts *loggingOptions) {
+		opts.responseErrorMessage = t
+	}
+}
+
+// WithErrorCollapsing collapses repeated errors: within a window, starting at the first occurrence of an error, only
+// the first occurrences (see CollapseBurst) are logged. When the window ends, a summary entry with the number of
+// suppressed occurrences is written. Errors are considered repeated when they have the same fingerprint (see
+// DefaultFingerprint and CollapseFingerprint).
+func WithErrorCollapsing(window time.Duration, options ...CollapseOption) Option {
+	collapser := newErrorCollapser(window, options...)
+	return func(opts *loggingOptions) {
+		opts.collapser = collapser
+	}
+}
+
+// WithAdditionalErrorHandler adds the fields of handler to the ones of the error handler already set (by default,
+// DefaultErrorHandler), instead of replacing it like WithErrorHandler does.
+func WithAdditionalErrorHandler(handler ErrorHandlerFunc) Option {
+	return func(opts *loggingOptions) {
+		opts.handleError = ChainErrorHandlers(opts.handleError, handler)
+	}
+}
+
+// WithDebugBuffering buffers, per call, the debug entries written through the logctx logger of the call that are
+// below the level of the logger. The buffered entries are written only when the call fails (or, see
+// DebugBufferLatencyThreshold, when it is slow), before its completed entry, and discarded otherwise. The completed
+// entry then tells how many entries were flushed ("grpc.debug_buffer.flushed") and dropped because the buffer was full
+// ("grpc.debug_buffer.dropped").
+func WithDebugBuffering(options ...DebugBufferOption) Option {
+	return func(opts *loggingOptions) {
+		opts.debugBuffer = &debugBufferOptions{size: defaultDebugBufferSize}
+		for _, opt := range options {
+			opt(opts.debugBuffer)
+		}
+		if opts.debugBuffer.size <= 0 {
+			opts.debugBuffer.size = defaultDebugBufferSize
+		}
+	}
+}
+
+// WithSlowThreshold sets the latency above which a call is slow. The completion of slow calls is logged even when
+// WithOperationCompleted(false) is set (or a Decider restricts the call to failures), at least at the level set by
+// WithSlowLevel (Warn by default), with the "grpc.slow" and "grpc.slow_threshold" fields. A zero threshold, the
+// default, disables the detection.
+func WithSlowThreshold(threshold time.Duration) Option {
+	return func(opts *loggingOptions) {
+		opts.slow.threshold = threshold
+	}
+}
+
+// WithMethodSlowThreshold overrides, for fullMethod (eg: "/package.Service/Method"), the threshold set by
+// WithSlowThreshold. A zero threshold disables the detection for the method.
+func WithMethodSlowThreshold(fullMethod string, threshold time.Duration) Option {
+	return func(opts *loggingOptions) {
+		if opts.slow.methods == nil {
+			opts.slow.methods = make(map[string]time.Duration)
+		}
+		opts.slow.methods[fullMethod] = threshold
+	}
+}
+
+// WithSlowLevel sets the minimum level used to log the completion of slow calls. The default is Warn.
+func WithSlowLevel(level zapcore.Level) Option {
+	return func(opts *loggingOptions) {
+		opts.slow.level = level
+	}
+}
+
+// WithDebugLogHeader lets authorized callers enable the debug logging of a single call by sending the "x-debug-log"
+// metadata key (see DebugLogKey) with a non empty value. For those calls, the logctx logger of the call is raised to
+// the debug level, the completion of the call is logged (even when WithOperationCompleted(false) is set) with the
+// "grpc.debug_log" field and, when no extractor is configured, the request and the response are logged using the proto
+// extractors. authorize is called for every call carrying the key, so not every caller can enable it. It panics if
+// authorize is nil.
+func WithDebugLogHeader(authorize DebugLogAuthorizer, options ...DebugLogOption) Option {
+	if authorize == nil {
+		panic("logging: WithDebugLogHeader requires an authorizer")
+	}
+	return func(opts *loggingOptions) {
+		opts.debugLog = &debugLogOptions{
+			key:       defaultDebugLogKey,
+			authorize: authorize,
+		}
+		for _, opt := range options {
+			opt(opts.debugLog)
+		}
+	}
+}
+
+// WithRuntimeConfig applies, to every call, the configuration of its method held by config (see MethodConfig), which
+// can be changed while the server is running. The configuration is applied before WithDebugLogHeader, so a call with
+// debug logging enabled still logs its payloads.
+func WithRuntimeConfig(config *RuntimeConfig) Option {
+	return func(opts *loggingOptions) {
+		opts.runtimeConfig = config
+	}
+}
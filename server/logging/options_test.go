@@ -39,3 +39,9 @@ func TestWithErrorHandler(t *testing.T) {
 	WithErrorHandler(func(ctx context.Context, err error) []zapcore.Field { return nil })(&opts)
 	assert.NotNil(t, opts.handleError)
 }
+
+func TestWithDurationField(t *testing.T) {
+	var opts loggingOptions
+	WithDurationField(DurationAsSeconds)(&opts)
+	assert.NotNil(t, opts.durationField)
+}
@@ -0,0 +1,81 @@
+package logging
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	fieldGRPCPeerAddress    = "grpc.peer.address"
+	fieldGRPCPeerAuthType   = "grpc.peer.auth_type"
+	fieldGRPCPeerTLSVersion = "grpc.peer.tls.version"
+	fieldGRPCPeerTLSCipher  = "grpc.peer.tls.cipher"
+	fieldGRPCPeerTLSSubject = "grpc.peer.tls.subject"
+	fieldGRPCPeerTLSSANs    = "grpc.peer.tls.sans"
+)
+
+var tlsVersionNames = map[uint16]string{
+	tls.VersionTLS10: "TLS 1.0",
+	tls.VersionTLS11: "TLS 1.1",
+	tls.VersionTLS12: "TLS 1.2",
+	tls.VersionTLS13: "TLS 1.3",
+}
+
+// buildPeerFields returns the fields describing the peer of the call: its address, the transport security and, when
+// the connection uses TLS, its version, cipher suite and the subject and SANs of the verified client certificate.
+func buildPeerFields(ctx context.Context) []zap.Field {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	fields := make([]zap.Field, 0, 6)
+	if p.Addr != nil {
+		fields = append(fields, zap.String(fieldGRPCPeerAddress, p.Addr.String()))
+	}
+	if p.AuthInfo == nil {
+		return fields
+	}
+	fields = append(fields, zap.String(fieldGRPCPeerAuthType, p.AuthInfo.AuthType()))
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return fields
+	}
+	state := tlsInfo.State
+	fields = append(
+		fields,
+		zap.String(fieldGRPCPeerTLSVersion, tlsVersionName(state.Version)),
+		zap.String(fieldGRPCPeerTLSCipher, tls.CipherSuiteName(state.CipherSuite)),
+	)
+	if len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return fields
+	}
+	cert := state.VerifiedChains[0][0]
+	sans := make([]string, 0, len(cert.DNSNames)+len(cert.EmailAddresses)+len(cert.IPAddresses)+len(cert.URIs))
+	sans = append(sans, cert.DNSNames...)
+	sans = append(sans, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	return append(
+		fields,
+		zap.String(fieldGRPCPeerTLSSubject, cert.Subject.String()),
+		zap.Strings(fieldGRPCPeerTLSSANs, sans),
+	)
+}
+
+func tlsVersionName(version uint16) string {
+	if name, ok := tlsVersionNames[version]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%04X", version)
+}
@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+func TestInterceptor_PeerFields(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}
+
+	t.Run("should log the address and the mTLS client certificate", func(t *testing.T) {
+		ctx, obs := createObserver()
+		ctx = peer.NewContext(ctx, &peer.Peer{
+			Addr: addr,
+			AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+				Version:     tls.VersionTLS13,
+				CipherSuite: tls.TLS_AES_128_GCM_SHA256,
+				VerifiedChains: [][]*x509.Certificate{{{
+					Subject:        pkix.Name{CommonName: "billing"},
+					DNSNames:       []string{"billing.internal"},
+					EmailAddresses: []string{"billing@example.com"},
+					IPAddresses:    []net.IP{net.IPv4(10, 0, 0, 1)},
+				}}},
+			}},
+		})
+		_, _ = UnaryInterceptor(WithPeerFields(true))(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		entries := obs.All()
+		require.Len(t, entries, 1)
+		fields := entries[0].ContextMap()
+		assert.Equal(t, "10.0.0.1:5000", fields[fieldGRPCPeerAddress])
+		assert.Equal(t, "tls", fields[fieldGRPCPeerAuthType])
+		assert.Equal(t, "TLS 1.3", fields[fieldGRPCPeerTLSVersion])
+		assert.Equal(t, "TLS_AES_128_GCM_SHA256", fields[fieldGRPCPeerTLSCipher])
+		assert.Equal(t, "CN=billing", fields[fieldGRPCPeerTLSSubject])
+		assert.Equal(t, []interface{}{"billing.internal", "billing@example.com", "10.0.0.1"}, fields[fieldGRPCPeerTLSSANs])
+	})
+
+	t.Run("should only log the address for insecure connections", func(t *testing.T) {
+		ctx, obs := createObserver()
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: addr})
+		_, _ = UnaryInterceptor(WithPeerFields(true))(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		entries := obs.All()
+		require.Len(t, entries, 1)
+		fields := entries[0].ContextMap()
+		assert.Equal(t, "10.0.0.1:5000", fields[fieldGRPCPeerAddress])
+		assert.NotContains(t, fields, fieldGRPCPeerAuthType)
+	})
+
+	t.Run("should not log the peer when disabled", func(t *testing.T) {
+		ctx, obs := createObserver()
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: addr})
+		_, _ = UnaryInterceptor()(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		entries := obs.All()
+		require.Len(t, entries, 1)
+		assert.NotContains(t, entries[0].ContextMap(), fieldGRPCPeerAddress)
+	})
+}
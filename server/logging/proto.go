@@ -0,0 +1,566 @@
+package logging
+
+import (
+	"context"
+	"encoding/base64"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const (
+	defaultProtoMaxDepth  = 10
+	defaultProtoMaxFields = 100
+
+	protoFieldType          = "@type"
+	protoFieldValue         = "value"
+	protoFieldFieldsOmitted = "$fields_omitted"
+	protoFieldTruncated     = "$truncated"
+	protoFieldOriginalSize  = "$original_size"
+	protoMaxDepthExceeded   = "<max depth exceeded>"
+
+	// protoScalarSize is the estimated encoded size of numbers, booleans, timestamps and durations, used to enforce
+	// the ProtoMaxSize budget.
+	protoScalarSize = 8
+)
+
+type protoOptions struct {
+	maxDepth       int
+	maxFields      int
+	redactor       *Redactor
+	maxStringLen   int
+	maxBytesLen    int
+	maxRepeated    int
+	maxEncodedSize int
+}
+
+// ProtoOption configures how proto messages are rendered by ProtoMessage, ProtoRequestExtractor and
+// ProtoResponseExtractor.
+type ProtoOption func(*protoOptions)
+
+func defaultProtoOptions() protoOptions {
+	return protoOptions{
+		maxDepth:  defaultProtoMaxDepth,
+		maxFields: defaultProtoMaxFields,
+		redactor:  debugRedactor,
+	}
+}
+
+// ProtoMaxDepth limits how deep nested messages are rendered. Messages deeper than the limit are replaced by a
+// placeholder. Zero, or a negative number, disables the limit. The default is 10.
+func ProtoMaxDepth(depth int) ProtoOption {
+	return func(opts *protoOptions) {
+		opts.maxDepth = depth
+	}
+}
+
+// ProtoMaxFields limits how many fields of each message are rendered. The number of fields left out is reported in
+// the "$fields_omitted" field. Zero, or a negative number, disables the limit. The default is 100.
+func ProtoMaxFields(fields int) ProtoOption {
+	return func(opts *protoOptions) {
+		opts.maxFields = fields
+	}
+}
+
+// ProtoMaxStringLength truncates string values longer than length bytes. Zero disables the limit, which is the
+// default.
+func ProtoMaxStringLength(length int) ProtoOption {
+	return func(opts *protoOptions) {
+		opts.maxStringLen = length
+	}
+}
+
+// ProtoMaxBytesLength truncates bytes values longer than length bytes. Zero disables the limit, which is the default.
+func ProtoMaxBytesLength(length int) ProtoOption {
+	return func(opts *protoOptions) {
+		opts.maxBytesLen = length
+	}
+}
+
+// ProtoMaxRepeated limits how many elements of repeated and map fields are rendered. Zero disables the limit, which
+// is the default.
+func ProtoMaxRepeated(elements int) ProtoOption {
+	return func(opts *protoOptions) {
+		opts.maxRepeated = elements
+	}
+}
+
+// ProtoMaxSize sets an approximate budget, in bytes, for the whole rendered message. Once it is exhausted, the
+// remaining fields and elements are left out and strings are cut to fit. Zero disables the limit, which is the
+// default.
+//
+// Whenever any of the limits truncates the message, the "$truncated" and "$original_size" (the proto.Size of the
+// message) fields are added to the rendered object.
+func ProtoMaxSize(size int) ProtoOption {
+	return func(opts *protoOptions) {
+		opts.maxEncodedSize = size
+	}
+}
+
+// ProtoRedactor redacts the sensitive fields, as defined by redactor, of the rendered messages. By default, only the
+// fields annotated with `debug_redact` are redacted. A nil redactor disables the redaction.
+func ProtoRedactor(redactor *Redactor) ProtoOption {
+	return func(opts *protoOptions) {
+		opts.redactor = redactor
+	}
+}
+
+// ProtoMessage returns a zapcore.ObjectMarshaler that renders any proto.Message using protoreflect. Well known types
+// (Timestamp, Duration, wrappers, Struct, Value, ListValue, FieldMask and Any) are rendered as their natural
+// representation. When msg, or the message packed in an Any, is a well known type other than Struct, its value is
+// rendered in the "value" field, as protojson does.
+func ProtoMessage(msg proto.Message, options ...ProtoOption) zapcore.ObjectMarshaler {
+	opts := defaultProtoOptions()
+	for _, opt := range options {
+		opt(&opts)
+	}
+	return &protoMessageMarshaler{msg: msg.ProtoReflect(), opts: &opts}
+}
+
+// ProtoRequestExtractor returns a request extractor, to be used with WithRequestExtractor, that renders requests
+// that are proto messages with ProtoMessage. Other requests are not logged.
+func ProtoRequestExtractor(options ...ProtoOption) func(ctx context.Context, req interface{}) (context.Context, zapcore.ObjectMarshaler, error) {
+	return func(ctx context.Context, req interface{}) (context.Context, zapcore.ObjectMarshaler, error) {
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return ctx, nil, nil
+		}
+		return ctx, ProtoMessage(msg, options...), nil
+	}
+}
+
+// ProtoResponseExtractor returns a response extractor, to be used with WithResponseExtractor, that renders responses
+// that are proto messages with ProtoMessage. Other responses are not logged.
+func ProtoResponseExtractor(options ...ProtoOption) func(ctx context.Context, resp interface{}) zapcore.ObjectMarshaler {
+	return func(ctx context.Context, resp interface{}) zapcore.ObjectMarshaler {
+		msg, ok := resp.(proto.Message)
+		if !ok || msg == nil {
+			return nil
+		}
+		return ProtoMessage(msg, options...)
+	}
+}
+
+// protoState is the state shared by all the marshalers involved in rendering a message.
+type protoState struct {
+	size      int
+	truncated bool
+}
+
+type protoMessageMarshaler struct {
+	msg   protoreflect.Message
+	opts  *protoOptions
+	depth int
+	// prefix is the path of the message being rendered (eg: "user." or "cards[*]."), used for redaction.
+	prefix string
+	// state is nil for the root message, which creates a new one every time it is marshaled.
+	state *protoState
+}
+
+func (m *protoMessageMarshaler) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	if !m.msg.IsValid() {
+		return nil
+	}
+	if m.state != nil {
+		return m.marshalFields(encoder)
+	}
+
+	root := *m
+	root.state = &protoState{}
+	if err := root.marshalFields(encoder); err != nil {
+		return err
+	}
+	if root.state.truncated {
+		encoder.AddBool(protoFieldTruncated, true)
+		encoder.AddInt(protoFieldOriginalSize, proto.Size(m.msg.Interface()))
+	}
+	return nil
+}
+
+// protoWellKnownValues are the well known types rendered as a single value, instead of an object.
+var protoWellKnownValues = map[protoreflect.FullName]struct{}{
+	"google.protobuf.Timestamp":   {},
+	"google.protobuf.Duration":    {},
+	"google.protobuf.DoubleValue": {},
+	"google.protobuf.FloatValue":  {},
+	"google.protobuf.Int64Value":  {},
+	"google.protobuf.UInt64Value": {},
+	"google.protobuf.Int32Value":  {},
+	"google.protobuf.UInt32Value": {},
+	"google.protobuf.BoolValue":   {},
+	"google.protobuf.StringValue": {},
+	"google.protobuf.BytesValue":  {},
+	"google.protobuf.FieldMask":   {},
+	"google.protobuf.Value":       {},
+	"google.protobuf.ListValue":   {},
+}
+
+func (m *protoMessageMarshaler) marshalFields(encoder zapcore.ObjectEncoder) error {
+	fields := m.msg.Descriptor().Fields()
+	switch name := m.msg.Descriptor().FullName(); name {
+	case "google.protobuf.Any":
+		return m.marshalAny(encoder)
+	case "google.protobuf.Struct":
+		fd := fields.ByNumber(1)
+		return (&protoMapMarshaler{parent: m, fd: fd, m: m.msg.Get(fd).Map(), path: m.prefix + "[*]"}).MarshalLogObject(encoder)
+	default:
+		// As there is no object to render them into, the well known types that are single values are rendered in the
+		// "value" field, as protojson does for the ones packed in an Any.
+		if _, ok := protoWellKnownValues[name]; ok {
+			return m.encodeMessage(objectValueEncoder{encoder, protoFieldValue}, m.msg, m.prefix+protoFieldValue)
+		}
+	}
+
+	added := 0
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if !m.msg.Has(fd) {
+			continue
+		}
+		if (m.opts.maxFields > 0 && added >= m.opts.maxFields) || m.budgetExhausted() {
+			m.state.truncated = true
+			encoder.AddInt(protoFieldFieldsOmitted, m.countRemaining(i))
+			break
+		}
+		path := m.prefix + string(fd.Name())
+		enc := objectValueEncoder{encoder, string(fd.Name())}
+		m.state.size += len(fd.Name())
+		if m.opts.redactor != nil {
+			if action, ok := m.opts.redactor.action(fd, path); ok {
+				if action == RedactDrop {
+					continue
+				}
+				added++
+				m.encodeRedacted(enc, fd, m.msg.Get(fd), action)
+				continue
+			}
+		}
+		added++
+		if err := m.encodeField(enc, fd, m.msg.Get(fd), path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *protoMessageMarshaler) countRemaining(from int) int {
+	fields := m.msg.Descriptor().Fields()
+	remaining := 0
+	for i := from; i < fields.Len(); i++ {
+		if m.msg.Has(fields.Get(i)) {
+			remaining++
+		}
+	}
+	return remaining
+}
+
+// marshalAny renders the message packed in a google.protobuf.Any, when its type can be resolved.
+func (m *protoMessageMarshaler) marshalAny(encoder zapcore.ObjectEncoder) error {
+	fields := m.msg.Descriptor().Fields()
+	typeURL := m.msg.Get(fields.ByNumber(1)).String()
+	value := m.msg.Get(fields.ByNumber(2)).Bytes()
+	encoder.AddString(protoFieldType, typeURL)
+
+	mt, err := protoregistry.GlobalTypes.FindMessageByURL(typeURL)
+	if err != nil {
+		encoder.AddBinary(protoFieldValue, value)
+		return nil
+	}
+	inner := mt.New()
+	if err := proto.Unmarshal(value, inner.Interface()); err != nil {
+		encoder.AddBinary(protoFieldValue, value)
+		return nil
+	}
+	return (&protoMessageMarshaler{msg: inner, opts: m.opts, depth: m.depth, prefix: m.prefix, state: m.state}).MarshalLogObject(encoder)
+}
+
+// budgetExhausted reports whether the ProtoMaxSize budget has been used up.
+func (m *protoMessageMarshaler) budgetExhausted() bool {
+	return m.opts.maxEncodedSize > 0 && m.state.size >= m.opts.maxEncodedSize
+}
+
+// limitLength returns the length a string (or bytes) value of size bytes should be cut to, given its own limit and
+// what is left of the ProtoMaxSize budget.
+func (m *protoMessageMarshaler) limitLength(size, limit int) int {
+	if m.opts.maxEncodedSize > 0 {
+		remaining := m.opts.maxEncodedSize - m.state.size
+		if remaining < 0 {
+			remaining = 0
+		}
+		if limit <= 0 || remaining < limit {
+			limit = remaining
+		}
+	}
+	if limit <= 0 && m.opts.maxEncodedSize <= 0 {
+		return size
+	}
+	if size > limit {
+		m.state.truncated = true
+		return limit
+	}
+	return size
+}
+
+// truncateString cuts str to the limits, without breaking UTF-8 sequences.
+func (m *protoMessageMarshaler) truncateString(str string) string {
+	n := m.limitLength(len(str), m.opts.maxStringLen)
+	for n > 0 && n < len(str) && !utf8.RuneStart(str[n]) {
+		n--
+	}
+	m.state.size += n
+	return str[:n]
+}
+
+// encodeRedacted renders the redacted value of a sensitive field.
+func (m *protoMessageMarshaler) encodeRedacted(enc valueEncoder, fd protoreflect.FieldDescriptor, v protoreflect.Value, action RedactAction) {
+	if action == RedactHash {
+		enc.String(m.truncateString(m.opts.redactor.hash(fd, v)))
+		return
+	}
+	enc.String(m.truncateString(redactedValue))
+}
+
+func (m *protoMessageMarshaler) encodeField(enc valueEncoder, fd protoreflect.FieldDescriptor, v protoreflect.Value, path string) error {
+	switch {
+	case fd.IsList():
+		return enc.Array(&protoListMarshaler{parent: m, fd: fd, list: v.List(), path: path + "[*]"})
+	case fd.IsMap():
+		return enc.Object(&protoMapMarshaler{parent: m, fd: fd, m: v.Map(), path: path + "[*]"})
+	default:
+		return m.encodeValue(enc, fd, v, path)
+	}
+}
+
+// encodeValue renders a single (non repeated) value of the field fd, found at path.
+func (m *protoMessageMarshaler) encodeValue(enc valueEncoder, fd protoreflect.FieldDescriptor, v protoreflect.Value, path string) error {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		m.state.size += protoScalarSize
+		enc.Bool(v.Bool())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			m.state.size += len(ev.Name())
+			enc.String(string(ev.Name()))
+		} else {
+			m.state.size += protoScalarSize
+			enc.Int64(int64(v.Enum()))
+		}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		m.state.size += protoScalarSize
+		enc.Int64(v.Int())
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		m.state.size += protoScalarSize
+		enc.Uint64(v.Uint())
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		m.state.size += protoScalarSize
+		enc.Float64(v.Float())
+	case protoreflect.StringKind:
+		enc.String(m.truncateString(v.String()))
+	case protoreflect.BytesKind:
+		b := v.Bytes()
+		b = b[:m.limitLength(len(b), m.opts.maxBytesLen)]
+		m.state.size += base64.StdEncoding.EncodedLen(len(b))
+		enc.Binary(b)
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return m.encodeMessage(enc, v.Message(), path)
+	}
+	return nil
+}
+
+// encodeMessage renders a nested message, handling the well known types.
+func (m *protoMessageMarshaler) encodeMessage(enc valueEncoder, msg protoreflect.Message, path string) error {
+	fields := msg.Descriptor().Fields()
+	switch msg.Descriptor().FullName() {
+	case "google.protobuf.Timestamp":
+		m.state.size += protoScalarSize
+		enc.Time(time.Unix(msg.Get(fields.ByNumber(1)).Int(), msg.Get(fields.ByNumber(2)).Int()).UTC())
+		return nil
+	case "google.protobuf.Duration":
+		m.state.size += protoScalarSize
+		enc.Duration(time.Duration(msg.Get(fields.ByNumber(1)).Int())*time.Second + time.Duration(msg.Get(fields.ByNumber(2)).Int()))
+		return nil
+	case "google.protobuf.DoubleValue", "google.protobuf.FloatValue", "google.protobuf.Int64Value",
+		"google.protobuf.UInt64Value", "google.protobuf.Int32Value", "google.protobuf.UInt32Value",
+		"google.protobuf.BoolValue", "google.protobuf.StringValue", "google.protobuf.BytesValue":
+		fd := fields.ByNumber(1)
+		return m.encodeValue(enc, fd, msg.Get(fd), path)
+	case "google.protobuf.FieldMask":
+		list := msg.Get(fields.ByNumber(1)).List()
+		paths := make([]string, 0, list.Len())
+		for i := 0; i < list.Len(); i++ {
+			paths = append(paths, list.Get(i).String())
+		}
+		enc.String(m.truncateString(strings.Join(paths, ",")))
+		return nil
+	}
+
+	if m.opts.maxDepth > 0 && m.depth+1 >= m.opts.maxDepth {
+		m.state.truncated = true
+		enc.String(protoMaxDepthExceeded)
+		return nil
+	}
+	nested := &protoMessageMarshaler{msg: msg, opts: m.opts, depth: m.depth + 1, prefix: path + ".", state: m.state}
+
+	switch msg.Descriptor().FullName() {
+	case "google.protobuf.Value":
+		return nested.encodeStructValue(enc, path)
+	case "google.protobuf.Struct":
+		fd := fields.ByNumber(1)
+		return enc.Object(&protoMapMarshaler{parent: nested, fd: fd, m: msg.Get(fd).Map(), path: path + "[*]"})
+	case "google.protobuf.ListValue":
+		fd := fields.ByNumber(1)
+		return enc.Array(&protoListMarshaler{parent: nested, fd: fd, list: msg.Get(fd).List(), path: path + "[*]"})
+	}
+	return enc.Object(nested)
+}
+
+// encodeStructValue renders a google.protobuf.Value as the value it holds.
+func (m *protoMessageMarshaler) encodeStructValue(enc valueEncoder, path string) error {
+	od := m.msg.Descriptor().Oneofs().ByName("kind")
+	fd := m.msg.WhichOneof(od)
+	if fd == nil || fd.Name() == "null_value" {
+		m.state.size += protoScalarSize
+		enc.Reflected(nil)
+		return nil
+	}
+	return m.encodeValue(enc, fd, m.msg.Get(fd), path)
+}
+
+type protoListMarshaler struct {
+	parent *protoMessageMarshaler
+	fd     protoreflect.FieldDescriptor
+	list   protoreflect.List
+	path   string
+}
+
+func (l *protoListMarshaler) MarshalLogArray(encoder zapcore.ArrayEncoder) error {
+	for i := 0; i < l.list.Len(); i++ {
+		if l.parent.elementsExhausted(i) {
+			break
+		}
+		if err := l.parent.encodeValue(arrayValueEncoder{encoder}, l.fd, l.list.Get(i), l.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type protoMapMarshaler struct {
+	parent *protoMessageMarshaler
+	fd     protoreflect.FieldDescriptor
+	m      protoreflect.Map
+	path   string
+}
+
+// MarshalLogObject renders the entries sorted by key, so the limits always leave out the same ones.
+func (p *protoMapMarshaler) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	for i, key := range sortedMapKeys(p.m) {
+		if p.parent.elementsExhausted(i) {
+			break
+		}
+		p.parent.state.size += len(key.String())
+		if err := p.parent.encodeValue(objectValueEncoder{encoder, key.String()}, p.fd.MapValue(), p.m.Get(key), p.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedMapKeys(m protoreflect.Map) []protoreflect.MapKey {
+	keys := make([]protoreflect.MapKey, 0, m.Len())
+	m.Range(func(key protoreflect.MapKey, _ protoreflect.Value) bool {
+		keys = append(keys, key)
+		return true
+	})
+	sort.Slice(keys, func(i, j int) bool {
+		switch a := keys[i].Interface().(type) {
+		case bool:
+			return !a && keys[j].Bool()
+		case int32, int64:
+			return keys[i].Int() < keys[j].Int()
+		case uint32, uint64:
+			return keys[i].Uint() < keys[j].Uint()
+		default:
+			return keys[i].String() < keys[j].String()
+		}
+	})
+	return keys
+}
+
+// elementsExhausted reports whether the element i of a repeated or map field should be left out, because of the
+// ProtoMaxRepeated limit or the ProtoMaxSize budget.
+func (m *protoMessageMarshaler) elementsExhausted(i int) bool {
+	if (m.opts.maxRepeated > 0 && i >= m.opts.maxRepeated) || m.budgetExhausted() {
+		m.state.truncated = true
+		return true
+	}
+	return false
+}
+
+// valueEncoder abstracts the difference between adding a value to a zapcore.ObjectEncoder (with a key) and appending
+// it to a zapcore.ArrayEncoder.
+type valueEncoder interface {
+	Bool(v bool)
+	Int64(v int64)
+	Uint64(v uint64)
+	Float64(v float64)
+	String(v string)
+	Binary(v []byte)
+	Time(v time.Time)
+	Duration(v time.Duration)
+	Reflected(v interface{})
+	Object(v zapcore.ObjectMarshaler) error
+	Array(v zapcore.ArrayMarshaler) error
+}
+
+type objectValueEncoder struct {
+	enc zapcore.ObjectEncoder
+	key string
+}
+
+func (e objectValueEncoder) Bool(v bool)              { e.enc.AddBool(e.key, v) }
+func (e objectValueEncoder) Int64(v int64)            { e.enc.AddInt64(e.key, v) }
+func (e objectValueEncoder) Uint64(v uint64)          { e.enc.AddUint64(e.key, v) }
+func (e objectValueEncoder) Float64(v float64)        { e.enc.AddFloat64(e.key, v) }
+func (e objectValueEncoder) String(v string)          { e.enc.AddString(e.key, v) }
+func (e objectValueEncoder) Binary(v []byte)          { e.enc.AddBinary(e.key, v) }
+func (e objectValueEncoder) Time(v time.Time)         { e.enc.AddTime(e.key, v) }
+func (e objectValueEncoder) Duration(v time.Duration) { e.enc.AddDuration(e.key, v) }
+func (e objectValueEncoder) Reflected(v interface{})  { _ = e.enc.AddReflected(e.key, v) }
+
+func (e objectValueEncoder) Object(v zapcore.ObjectMarshaler) error {
+	return e.enc.AddObject(e.key, v)
+}
+
+func (e objectValueEncoder) Array(v zapcore.ArrayMarshaler) error {
+	return e.enc.AddArray(e.key, v)
+}
+
+type arrayValueEncoder struct {
+	enc zapcore.ArrayEncoder
+}
+
+func (e arrayValueEncoder) Bool(v bool)              { e.enc.AppendBool(v) }
+func (e arrayValueEncoder) Int64(v int64)            { e.enc.AppendInt64(v) }
+func (e arrayValueEncoder) Uint64(v uint64)          { e.enc.AppendUint64(v) }
+func (e arrayValueEncoder) Float64(v float64)        { e.enc.AppendFloat64(v) }
+func (e arrayValueEncoder) String(v string)          { e.enc.AppendString(v) }
+func (e arrayValueEncoder) Binary(v []byte)          { e.enc.AppendString(base64.StdEncoding.EncodeToString(v)) }
+func (e arrayValueEncoder) Time(v time.Time)         { e.enc.AppendTime(v) }
+func (e arrayValueEncoder) Duration(v time.Duration) { e.enc.AppendDuration(v) }
+func (e arrayValueEncoder) Reflected(v interface{})  { _ = e.enc.AppendReflected(v) }
+
+func (e arrayValueEncoder) Object(v zapcore.ObjectMarshaler) error {
+	return e.enc.AppendObject(v)
+}
+
+func (e arrayValueEncoder) Array(v zapcore.ArrayMarshaler) error {
+	return e.enc.AppendArray(v)
+}
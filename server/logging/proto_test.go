@@ -0,0 +1,213 @@
+package logging
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func marshalProto(t *testing.T, m zapcore.ObjectMarshaler) map[string]interface{} {
+	t.Helper()
+	enc := zapcore.NewMapObjectEncoder()
+	require.NoError(t, m.MarshalLogObject(enc))
+	return enc.Fields
+}
+
+func TestProtoMessage(t *testing.T) {
+	t.Run("should render nested and repeated messages", func(t *testing.T) {
+		got := marshalProto(t, ProtoMessage(&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: "name", Description: "required"},
+				{Field: "age", Description: "too young"},
+			},
+		}))
+		assert.Equal(t, map[string]interface{}{
+			"field_violations": []interface{}{
+				map[string]interface{}{"field": "name", "description": "required"},
+				map[string]interface{}{"field": "age", "description": "too young"},
+			},
+		}, got)
+	})
+
+	t.Run("should render maps", func(t *testing.T) {
+		got := marshalProto(t, ProtoMessage(&errdetails.ErrorInfo{
+			Reason:   "REASON",
+			Metadata: map[string]string{"key": "value"},
+		}))
+		assert.Equal(t, map[string]interface{}{
+			"reason":   "REASON",
+			"metadata": map[string]interface{}{"key": "value"},
+		}, got)
+	})
+
+	t.Run("should render well known types", func(t *testing.T) {
+		ts := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+		st, err := structpb.NewStruct(map[string]interface{}{
+			"name": "john",
+			"tags": []interface{}{"a", true},
+			"none": nil,
+		})
+		require.NoError(t, err)
+
+		got := marshalProto(t, ProtoMessage(&structpb.ListValue{Values: []*structpb.Value{
+			structpb.NewStructValue(st),
+		}}))
+		assert.Equal(t, map[string]interface{}{
+			"value": []interface{}{
+				map[string]interface{}{
+					"name": "john",
+					"tags": []interface{}{"a", true},
+					"none": nil,
+				},
+			},
+		}, got)
+
+		got = marshalProto(t, ProtoMessage(st))
+		assert.Equal(t, map[string]interface{}{
+			"name": "john",
+			"tags": []interface{}{"a", true},
+			"none": nil,
+		}, got)
+
+		got = marshalProto(t, ProtoMessage(timestamppb.New(ts)))
+		assert.Equal(t, map[string]interface{}{"value": ts}, got)
+
+		got = marshalProto(t, ProtoMessage(&errdetails.RetryInfo{RetryDelay: durationpb.New(time.Second)}))
+		assert.Equal(t, map[string]interface{}{"retry_delay": time.Second}, got)
+
+		anyMsg, err := anypb.New(timestamppb.New(ts))
+		require.NoError(t, err)
+		got = marshalProto(t, ProtoMessage(anyMsg))
+		assert.Equal(t, map[string]interface{}{
+			"@type": "type.googleapis.com/google.protobuf.Timestamp",
+			"value": ts,
+		}, got)
+
+		anyMsg, err = anypb.New(st)
+		require.NoError(t, err)
+		got = marshalProto(t, ProtoMessage(anyMsg))
+		assert.Equal(t, map[string]interface{}{
+			"@type": "type.googleapis.com/google.protobuf.Struct",
+			"name":  "john",
+			"tags":  []interface{}{"a", true},
+			"none":  nil,
+		}, got)
+	})
+
+	t.Run("should render the same map entries within the limits", func(t *testing.T) {
+		msg := &errdetails.ErrorInfo{Metadata: map[string]string{"d": "4", "b": "2", "a": "1", "c": "3", "e": "5"}}
+		for i := 0; i < 10; i++ {
+			got := marshalProto(t, ProtoMessage(msg, ProtoMaxRepeated(2)))
+			assert.Equal(t, map[string]interface{}{"a": "1", "b": "2"}, got["metadata"])
+		}
+	})
+
+	t.Run("should render nested wrappers and timestamps as their values", func(t *testing.T) {
+		ts := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+		m := &protoMessageMarshaler{opts: &protoOptions{}, state: &protoState{}}
+		enc := zapcore.NewMapObjectEncoder()
+		require.NoError(t, m.encodeMessage(objectValueEncoder{enc, "wrapper"}, wrapperspb.String("value").ProtoReflect(), "wrapper"))
+		require.NoError(t, m.encodeMessage(objectValueEncoder{enc, "timestamp"}, timestamppb.New(ts).ProtoReflect(), "timestamp"))
+		assert.Equal(t, map[string]interface{}{"wrapper": "value", "timestamp": ts}, enc.Fields)
+	})
+
+	t.Run("should respect the max depth", func(t *testing.T) {
+		got := marshalProto(t, ProtoMessage(&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "name"}},
+		}, ProtoMaxDepth(1)))
+		assert.Equal(t, map[string]interface{}{
+			"field_violations":     []interface{}{protoMaxDepthExceeded},
+			protoFieldTruncated:    true,
+			protoFieldOriginalSize: 8,
+		}, got)
+	})
+
+	t.Run("should respect the max fields", func(t *testing.T) {
+		msg := &errdetails.ResourceInfo{
+			ResourceType: "type",
+			ResourceName: "name",
+			Owner:        "owner",
+		}
+		got := marshalProto(t, ProtoMessage(msg, ProtoMaxFields(1)))
+		assert.Equal(t, map[string]interface{}{
+			"resource_type":         "type",
+			protoFieldFieldsOmitted: 2,
+			protoFieldTruncated:     true,
+			protoFieldOriginalSize:  proto.Size(msg),
+		}, got)
+	})
+
+	t.Run("should truncate strings and bytes", func(t *testing.T) {
+		got := marshalProto(t, ProtoMessage(&errdetails.DebugInfo{
+			Detail: "ação demorada",
+		}, ProtoMaxStringLength(3)))
+		assert.Equal(t, "aç", got["detail"], "should not break multi-byte characters")
+		assert.Equal(t, true, got[protoFieldTruncated])
+
+		got = marshalProto(t, ProtoMessage(wrapperspb.Bytes([]byte("1234567890")), ProtoMaxBytesLength(4)))
+		assert.Equal(t, []byte("1234"), got["value"])
+		assert.Equal(t, true, got[protoFieldTruncated])
+		assert.Equal(t, 12, got[protoFieldOriginalSize])
+	})
+
+	t.Run("should limit repeated and map elements", func(t *testing.T) {
+		got := marshalProto(t, ProtoMessage(&errdetails.DebugInfo{
+			StackEntries: []string{"a", "b", "c"},
+		}, ProtoMaxRepeated(2)))
+		assert.Equal(t, []interface{}{"a", "b"}, got["stack_entries"])
+		assert.Equal(t, true, got[protoFieldTruncated])
+
+		got = marshalProto(t, ProtoMessage(&errdetails.ErrorInfo{
+			Metadata: map[string]string{"a": "1", "b": "2"},
+		}, ProtoMaxRepeated(1)))
+		assert.Len(t, got["metadata"], 1)
+		assert.Equal(t, true, got[protoFieldTruncated])
+	})
+
+	t.Run("should enforce the size budget", func(t *testing.T) {
+		msg := &errdetails.ResourceInfo{
+			ResourceType: "type",
+			ResourceName: "a very long resource name",
+			Owner:        "owner",
+		}
+		got := marshalProto(t, ProtoMessage(msg, ProtoMaxSize(41)))
+		assert.Equal(t, map[string]interface{}{
+			"resource_type":         "type",
+			"resource_name":         "a very long",
+			protoFieldFieldsOmitted: 1,
+			protoFieldTruncated:     true,
+			protoFieldOriginalSize:  proto.Size(msg),
+		}, got)
+	})
+
+	t.Run("should not mark messages within the limits as truncated", func(t *testing.T) {
+		m := ProtoMessage(&errdetails.DebugInfo{Detail: "detail"}, ProtoMaxSize(100), ProtoMaxStringLength(10))
+		assert.Equal(t, map[string]interface{}{"detail": "detail"}, marshalProto(t, m))
+		assert.Equal(t, map[string]interface{}{"detail": "detail"}, marshalProto(t, m), "the budget should be reset for every marshal")
+	})
+}
+
+func TestProtoExtractors(t *testing.T) {
+	ctx := context.Background()
+	_, obj, err := ProtoRequestExtractor()(ctx, &errdetails.RequestInfo{RequestId: "123"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"request_id": "123"}, marshalProto(t, obj))
+
+	_, obj, err = ProtoRequestExtractor()(ctx, "not a proto")
+	require.NoError(t, err)
+	assert.Nil(t, obj)
+
+	obj = ProtoResponseExtractor()(ctx, &errdetails.RequestInfo{ServingData: "data"})
+	assert.Equal(t, map[string]interface{}{"serving_data": "data"}, marshalProto(t, obj))
+}
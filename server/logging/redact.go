@@ -0,0 +1,244 @@
+package logging
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/jamillosantos/go-grpc-interceptors/internal/logfields"
+)
+
+const redactedValue = "[REDACTED]"
+
+// RedactAction is what is done with the value of a sensitive field.
+type RedactAction int
+
+const (
+	// RedactMask replaces the value by "[REDACTED]".
+	RedactMask RedactAction = iota
+	// RedactHash replaces the value by its SHA-256 ("sha256:<hex>"), so equal values can still be correlated.
+	RedactHash
+	// RedactDrop omits the field altogether.
+	RedactDrop
+)
+
+var redactPathRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\[\*\])?$`)
+
+// debugRedactor is the default redactor of the rendered messages, which only honours `debug_redact`.
+var debugRedactor = &Redactor{
+	debugRedact:   true,
+	defaultAction: RedactMask,
+	paths:         make(map[string]RedactAction),
+}
+
+// Redactor decides which fields of proto messages are sensitive and how they are redacted. Fields are considered
+// sensitive when they are annotated with the standard `debug_redact` option, with a custom annotation (see
+// RedactAnnotation) or when they match one of the paths given by RedactPath.
+type Redactor struct {
+	debugRedact      bool
+	annotation       protoreflect.ExtensionType
+	annotationAction RedactAction
+	defaultAction    RedactAction
+	paths            map[string]RedactAction
+	err              error
+}
+
+// RedactOption configures a Redactor.
+type RedactOption func(*Redactor)
+
+// NewRedactor creates a Redactor. It fails when any of the given paths is malformed.
+func NewRedactor(options ...RedactOption) (*Redactor, error) {
+	r := &Redactor{
+		debugRedact:   true,
+		defaultAction: RedactMask,
+		paths:         make(map[string]RedactAction),
+	}
+	for _, opt := range options {
+		opt(r)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r, nil
+}
+
+// RedactDebugRedact enables, or disables, honouring the `debug_redact` field option. It is enabled by default and the
+// fields annotated with it are redacted with the action set by RedactDefaultAction.
+func RedactDebugRedact(enable bool) RedactOption {
+	return func(r *Redactor) {
+		r.debugRedact = enable
+	}
+}
+
+// RedactDefaultAction sets the action applied to the fields annotated with `debug_redact`. The default is RedactMask.
+func RedactDefaultAction(action RedactAction) RedactOption {
+	return func(r *Redactor) {
+		r.defaultAction = action
+	}
+}
+
+// RedactAnnotation redacts, with the given action, the fields annotated with a custom bool extension of
+// google.protobuf.FieldOptions (eg: `string password = 1 [(mycompany.sensitive) = true];`).
+func RedactAnnotation(annotation protoreflect.ExtensionType, action RedactAction) RedactOption {
+	return func(r *Redactor) {
+		r.annotation = annotation
+		r.annotationAction = action
+	}
+}
+
+// RedactPath redacts, with the given action, the field at path. A path is a dot separated list of field names, where
+// repeated and map fields are followed by "[*]" to reach the fields of their elements (eg: "user.password" or
+// "cards[*].number"). A path cannot end with "[*]": whole repeated and map fields are redacted by their own path (eg:
+// "user.tokens").
+func RedactPath(path string, action RedactAction) RedactOption {
+	return func(r *Redactor) {
+		for _, segment := range strings.Split(path, ".") {
+			if !redactPathRegexp.MatchString(segment) || strings.HasSuffix(path, "[*]") {
+				if r.err == nil {
+					r.err = fmt.Errorf("invalid redact path %q", path)
+				}
+				return
+			}
+		}
+		r.paths[path] = action
+	}
+}
+
+// action returns the action to be applied to the field fd found at path, and false when it is not sensitive.
+func (r *Redactor) action(fd protoreflect.FieldDescriptor, path string) (RedactAction, bool) {
+	if action, ok := r.paths[path]; ok {
+		return action, true
+	}
+	opts, ok := fd.Options().(*descriptorpb.FieldOptions)
+	if !ok || opts == nil {
+		return 0, false
+	}
+	if r.annotation != nil && proto.HasExtension(opts, r.annotation) {
+		if v, ok := proto.GetExtension(opts, r.annotation).(bool); ok && v {
+			return r.annotationAction, true
+		}
+	}
+	if r.debugRedact && opts.GetDebugRedact() {
+		return r.defaultAction, true
+	}
+	return 0, false
+}
+
+// hash returns the representation of v when it is redacted with RedactHash.
+func (r *Redactor) hash(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	var data []byte
+	switch {
+	case fd.IsList() || fd.IsMap():
+		data = []byte(fmt.Sprint(v.Interface()))
+	case fd.Kind() == protoreflect.BytesKind:
+		data = v.Bytes()
+	case fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind:
+		data, _ = proto.MarshalOptions{Deterministic: true}.Marshal(v.Message().Interface())
+	default:
+		data = []byte(v.String())
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Redact returns a copy of msg with its sensitive fields redacted. As the copy must still be a valid message, masked
+// and hashed values are only replaced on string and bytes fields, any other field is cleared.
+func (r *Redactor) Redact(msg proto.Message) proto.Message {
+	clone := proto.Clone(msg)
+	r.redactMessage(clone.ProtoReflect(), "")
+	return clone
+}
+
+func (r *Redactor) redactMessage(msg protoreflect.Message, prefix string) {
+	type sensitiveField struct {
+		fd     protoreflect.FieldDescriptor
+		v      protoreflect.Value
+		action RedactAction
+	}
+	// The message cannot be changed while it is ranged, so the sensitive fields are redacted afterwards.
+	var sensitive []sensitiveField
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		path := prefix + string(fd.Name())
+		if action, ok := r.action(fd, path); ok {
+			sensitive = append(sensitive, sensitiveField{fd, v, action})
+			return true
+		}
+		switch {
+		case fd.IsList() && fd.Kind() == protoreflect.MessageKind:
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				r.redactMessage(list.Get(i).Message(), path+"[*].")
+			}
+		case fd.IsMap() && fd.MapValue().Kind() == protoreflect.MessageKind:
+			v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+				r.redactMessage(mv.Message(), path+"[*].")
+				return true
+			})
+		case !fd.IsList() && !fd.IsMap() && fd.Kind() == protoreflect.MessageKind:
+			r.redactMessage(v.Message(), path+".")
+		}
+		return true
+	})
+	for _, f := range sensitive {
+		r.redactValue(msg, f.fd, f.v, f.action)
+	}
+}
+
+func (r *Redactor) redactValue(msg protoreflect.Message, fd protoreflect.FieldDescriptor, v protoreflect.Value, action RedactAction) {
+	if action == RedactDrop || fd.IsList() || fd.IsMap() {
+		msg.Clear(fd)
+		return
+	}
+	replacement := redactedValue
+	if action == RedactHash {
+		replacement = r.hash(fd, v)
+	}
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		msg.Set(fd, protoreflect.ValueOfString(replacement))
+	case protoreflect.BytesKind:
+		msg.Set(fd, protoreflect.ValueOfBytes([]byte(replacement)))
+	default:
+		msg.Clear(fd)
+	}
+}
+
+// payloadRedactor returns the redactor of the payloads logged without an extractor configured by the user (see
+// WithRuntimeConfig and WithDebugLogHeader): the one set by WithRedactor or, when there is none, one honouring
+// `debug_redact`.
+func payloadRedactor(opts loggingOptions) *Redactor {
+	if opts.redactor != nil {
+		return opts.redactor
+	}
+	return debugRedactor
+}
+
+// redactError returns an error with the message and the kind of err, but with the details of its status redacted. If
+// err does not carry a status with details, it is returned as it is.
+func (r *Redactor) redactError(err error) error {
+	st, ok := logfields.StatusFromError(err)
+	if !ok || len(st.Proto().GetDetails()) == 0 {
+		return err
+	}
+	stProto := st.Proto()
+	for i, detail := range stProto.Details {
+		msg, err := detail.UnmarshalNew()
+		if err != nil {
+			continue
+		}
+		redacted, err := anypb.New(r.Redact(msg))
+		if err != nil {
+			continue
+		}
+		stProto.Details[i] = redacted
+	}
+	return &logfields.RedactedError{Err: err, Status: status.FromProto(stProto)}
+}
@@ -0,0 +1,300 @@
+package logging
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// createRedactTestTypes builds, at runtime, the following proto definitions:
+//
+//	extend google.protobuf.FieldOptions { bool sensitive = 50000; }
+//
+//	message Card { string number = 1; string holder = 2; }
+//	message User {
+//	  string name = 1;
+//	  string password = 2 [debug_redact = true];
+//	  string token = 3 [(sensitive) = true];
+//	  repeated Card cards = 4;
+//	}
+func createRedactTestTypes(t *testing.T) (protoreflect.MessageDescriptor, protoreflect.ExtensionType) {
+	t.Helper()
+
+	extFile, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("redact_ext_test.proto"),
+		Package:    proto.String("test"),
+		Dependency: []string{"google/protobuf/descriptor.proto"},
+		Extension: []*descriptorpb.FieldDescriptorProto{{
+			Name:     proto.String("sensitive"),
+			Number:   proto.Int32(50000),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_BOOL.Enum(),
+			Extendee: proto.String(".google.protobuf.FieldOptions"),
+		}},
+	}, protoregistry.GlobalFiles)
+	require.NoError(t, err)
+	xt := dynamicpb.NewExtensionType(extFile.Extensions().Get(0))
+
+	tokenOpts := &descriptorpb.FieldOptions{}
+	proto.SetExtension(tokenOpts, xt, true)
+
+	files := new(protoregistry.Files)
+	require.NoError(t, files.RegisterFile(extFile))
+	stringField := func(name string, number int32, opts *descriptorpb.FieldOptions) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:    proto.String(name),
+			Number:  proto.Int32(number),
+			Label:   descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:    descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			Options: opts,
+		}
+	}
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("redact_test.proto"),
+		Package:    proto.String("test"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"redact_ext_test.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Card"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					stringField("number", 1, nil),
+					stringField("holder", 2, nil),
+				},
+			},
+			{
+				Name: proto.String("User"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					stringField("name", 1, nil),
+					stringField("password", 2, &descriptorpb.FieldOptions{DebugRedact: proto.Bool(true)}),
+					stringField("token", 3, tokenOpts),
+					{
+						Name:     proto.String("cards"),
+						Number:   proto.Int32(4),
+						Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+						TypeName: proto.String(".test.Card"),
+					},
+				},
+			},
+		},
+	}, files)
+	require.NoError(t, err)
+	return file.Messages().ByName("User"), xt
+}
+
+func newRedactTestUser(md protoreflect.MessageDescriptor) proto.Message {
+	user := dynamicpb.NewMessage(md)
+	user.Set(md.Fields().ByName("name"), protoreflect.ValueOfString("john"))
+	user.Set(md.Fields().ByName("password"), protoreflect.ValueOfString("secret"))
+	user.Set(md.Fields().ByName("token"), protoreflect.ValueOfString("token"))
+	cards := user.Mutable(md.Fields().ByName("cards")).List()
+	card := cards.NewElement()
+	card.Message().Set(card.Message().Descriptor().Fields().ByName("number"), protoreflect.ValueOfString("4111"))
+	card.Message().Set(card.Message().Descriptor().Fields().ByName("holder"), protoreflect.ValueOfString("john"))
+	cards.Append(card)
+	return user
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func TestNewRedactor(t *testing.T) {
+	_, err := NewRedactor(RedactPath("user.password", RedactMask), RedactPath("cards[*].number", RedactHash))
+	require.NoError(t, err)
+
+	_, err = NewRedactor(RedactPath("user..password", RedactMask))
+	assert.Error(t, err)
+
+	_, err = NewRedactor(RedactPath("cards[0].number", RedactMask))
+	assert.Error(t, err)
+
+	_, err = NewRedactor(RedactPath("cards[*]", RedactMask))
+	assert.Error(t, err, "paths to the elements of repeated fields would never match")
+
+	_, err = NewRedactor(RedactPath("user.tokens[*]", RedactMask))
+	assert.Error(t, err)
+}
+
+func TestProtoRedactor(t *testing.T) {
+	md, xt := createRedactTestTypes(t)
+	user := newRedactTestUser(md)
+
+	t.Run("should redact annotated fields and paths while rendering", func(t *testing.T) {
+		r, err := NewRedactor(
+			RedactAnnotation(xt, RedactDrop),
+			RedactPath("cards[*].number", RedactHash),
+		)
+		require.NoError(t, err)
+
+		got := marshalProto(t, ProtoMessage(user, ProtoRedactor(r)))
+		assert.Equal(t, map[string]interface{}{
+			"name":     "john",
+			"password": redactedValue,
+			"cards": []interface{}{
+				map[string]interface{}{"number": sha256Hex("4111"), "holder": "john"},
+			},
+		}, got)
+	})
+
+	t.Run("should not honour debug_redact when disabled", func(t *testing.T) {
+		r, err := NewRedactor(RedactDebugRedact(false))
+		require.NoError(t, err)
+
+		got := marshalProto(t, ProtoMessage(user, ProtoRedactor(r)))
+		assert.Equal(t, "secret", got["password"])
+	})
+
+	t.Run("should honour debug_redact by default", func(t *testing.T) {
+		got := marshalProto(t, ProtoMessage(user))
+		assert.Equal(t, redactedValue, got["password"])
+		assert.Equal(t, "token", got["token"])
+	})
+
+	t.Run("should not redact without a redactor", func(t *testing.T) {
+		got := marshalProto(t, ProtoMessage(user, ProtoRedactor(nil)))
+		assert.Equal(t, "secret", got["password"])
+	})
+
+	t.Run("should redact a copy of the message", func(t *testing.T) {
+		r, err := NewRedactor(RedactAnnotation(xt, RedactMask), RedactPath("cards[*].holder", RedactDrop))
+		require.NoError(t, err)
+
+		redacted := r.Redact(user).ProtoReflect()
+		assert.Equal(t, redactedValue, redacted.Get(md.Fields().ByName("password")).String())
+		assert.Equal(t, redactedValue, redacted.Get(md.Fields().ByName("token")).String())
+		card := redacted.Get(md.Fields().ByName("cards")).List().Get(0).Message()
+		assert.False(t, card.Has(card.Descriptor().Fields().ByName("holder")))
+
+		assert.Equal(t, "secret", user.ProtoReflect().Get(md.Fields().ByName("password")).String())
+	})
+}
+
+func TestWithProtoPayloads_Redact(t *testing.T) {
+	md, xt := createRedactTestTypes(t)
+
+	call := func(options ...Option) map[string]interface{} {
+		ctx, obs := createObserver()
+		_, _ = UnaryInterceptor(options...)(ctx, newRedactTestUser(md), &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		entries := obs.All()
+		require.Len(t, entries, 1)
+		return entries[0].ContextMap()[fieldGRPCRequest].(map[string]interface{})
+	}
+
+	t.Run("should honour debug_redact by default", func(t *testing.T) {
+		request := call(WithProtoPayloads())
+		assert.Equal(t, redactedValue, request["password"])
+		assert.Equal(t, "token", request["token"])
+	})
+
+	t.Run("should use the redactor set by WithRedactor, in any order", func(t *testing.T) {
+		r, err := NewRedactor(RedactAnnotation(xt, RedactMask))
+		require.NoError(t, err)
+
+		request := call(WithProtoPayloads(), WithRedactor(r))
+		assert.Equal(t, redactedValue, request["password"])
+		assert.Equal(t, redactedValue, request["token"])
+
+		request = call(WithRedactor(r), WithProtoPayloads())
+		assert.Equal(t, redactedValue, request["token"])
+	})
+
+	t.Run("should prefer the ProtoRedactor given to WithProtoPayloads", func(t *testing.T) {
+		r, err := NewRedactor(RedactAnnotation(xt, RedactMask))
+		require.NoError(t, err)
+
+		request := call(WithRedactor(r), WithProtoPayloads(ProtoRedactor(nil)))
+		assert.Equal(t, "secret", request["password"])
+		assert.Equal(t, "token", request["token"])
+	})
+
+	t.Run("should keep an extractor set after WithProtoPayloads", func(t *testing.T) {
+		ctx, obs := createObserver()
+		_, _ = UnaryInterceptor(WithProtoPayloads(), WithRequestExtractor(func(ctx context.Context, req interface{}) (context.Context, zapcore.ObjectMarshaler, error) {
+			return ctx, nil, nil
+		}))(ctx, newRedactTestUser(md), &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return newRedactTestUser(md), nil
+		})
+		fields := obs.All()[0].ContextMap()
+		assert.NotContains(t, fields, fieldGRPCRequest)
+		assert.Equal(t, redactedValue, fields[fieldGRPCResponse].(map[string]interface{})["password"])
+	})
+}
+
+func TestInterceptor_RedactErrorDetails(t *testing.T) {
+	r, err := NewRedactor(RedactPath("metadata", RedactDrop), RedactPath("reason", RedactMask))
+	require.NoError(t, err)
+
+	st, err := status.New(codes.PermissionDenied, "denied").WithDetails(&errdetails.ErrorInfo{
+		Reason:   "TOKEN_LEAKED",
+		Domain:   "example.com",
+		Metadata: map[string]string{"token": "secret"},
+	})
+	require.NoError(t, err)
+
+	ctx, obs := createObserver()
+	_, gotErr := UnaryInterceptor(WithRedactor(r))(ctx, nil, &grpc.UnaryServerInfo{
+		FullMethod: "/pkg.Service/Method",
+	}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, st.Err()
+	})
+	assert.Equal(t, st.Err(), gotErr, "the error returned to the client should not be redacted")
+
+	entries := obs.All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"$type":  "ErrorInfo",
+		"reason": redactedValue,
+		"domain": "example.com",
+	}}, entries[0].ContextMap()[fieldGRPCErrorDetails])
+}
+
+func TestInterceptor_RedactWrappedErrorDetails(t *testing.T) {
+	r, err := NewRedactor(RedactPath("reason", RedactMask))
+	require.NoError(t, err)
+
+	st, err := status.New(codes.PermissionDenied, "denied").WithDetails(&errdetails.ErrorInfo{Reason: "TOKEN_LEAKED"})
+	require.NoError(t, err)
+
+	ctx, obs := createObserver()
+	_, _ = UnaryInterceptor(WithRedactor(r))(ctx, nil, &grpc.UnaryServerInfo{
+		FullMethod: "/pkg.Service/Method",
+	}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, wrappedError{st.Err()}
+	})
+
+	entries := obs.All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "wrapped: rpc error: code = PermissionDenied desc = denied", fields["error"], "the error should keep its message")
+	assert.Equal(t, ErrorKindWrappedStatus, fields[fieldGRPCErrorKind])
+	assert.Equal(t, "Unknown", fields[fieldGRPCStatus])
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"$type":  "ErrorInfo",
+		"reason": redactedValue,
+		"domain": "",
+	}}, fields[fieldGRPCErrorDetails])
+}
@@ -0,0 +1,146 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+
+	"github.com/jamillosantos/logctx"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	fieldGRPCRequestID = "grpc.request_id"
+
+	defaultRequestIDKey = "x-request-id"
+	// maxRequestIDLength is the maximum length of a request ID received from the client. Longer (or non printable)
+	// IDs are replaced by a generated one.
+	maxRequestIDLength = 128
+)
+
+type ctxKeyRequestID struct{}
+
+type requestIDOptions struct {
+	key       string
+	generator func() string
+	echo      bool
+}
+
+// RequestIDOption configures how WithRequestID reads, generates and propagates request IDs.
+type RequestIDOption func(*requestIDOptions)
+
+func defaultRequestIDOptions() *requestIDOptions {
+	return &requestIDOptions{
+		key:       defaultRequestIDKey,
+		generator: NewUUID,
+		echo:      true,
+	}
+}
+
+// RequestIDKey sets the metadata key the request ID is read from (and echoed to). The default is "x-request-id".
+func RequestIDKey(key string) RequestIDOption {
+	return func(opts *requestIDOptions) {
+		opts.key = key
+	}
+}
+
+// RequestIDGenerator sets the function that generates request IDs for calls that do not carry one. The default is
+// NewUUID. See also NewULID.
+func RequestIDGenerator(generator func() string) RequestIDOption {
+	return func(opts *requestIDOptions) {
+		opts.generator = generator
+	}
+}
+
+// RequestIDEcho enables, or disables, sending the request ID back to the client in the response headers. It is
+// enabled by default.
+func RequestIDEcho(enable bool) RequestIDOption {
+	return func(opts *requestIDOptions) {
+		opts.echo = enable
+	}
+}
+
+// RequestIDFromContext returns the request ID of the call, when WithRequestID is enabled.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(ctxKeyRequestID{}).(string)
+	return id, ok
+}
+
+// withRequestID reads the request ID from the incoming metadata (or generates a new one), adds it to the context and
+// to its logctx logger and, when enabled, echoes it in the response headers.
+func withRequestID(ctx context.Context, opts *requestIDOptions, naming NamingScheme) context.Context {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(opts.key); len(values) > 0 && isValidRequestID(values[0]) {
+			id = values[0]
+		}
+	}
+	if id == "" {
+		id = opts.generator()
+	}
+	if opts.echo {
+		// Fails only when there is no transport stream in the context, in which case there is no client to echo to.
+		_ = grpc.SetHeader(ctx, metadata.Pairs(opts.key, id))
+	}
+	ctx = context.WithValue(ctx, ctxKeyRequestID{}, id)
+	return logctx.WithFields(ctx, naming.rename([]zap.Field{zap.String(fieldGRPCRequestID, id)})...)
+}
+
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// NewUUID generates a random (version 4) UUID.
+func NewUUID() string {
+	var u [16]byte
+	_, _ = rand.Read(u[:])
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID generates a ULID (https://github.com/ulid/spec): a lexicographically sortable ID made of a millisecond
+// timestamp followed by 80 random bits.
+func NewULID() string {
+	var u [16]byte
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(time.Now().UnixMilli()))
+	copy(u[0:6], ts[2:])
+	_, _ = rand.Read(u[6:])
+
+	// 128 bits are encoded in 26 characters of 5 bits each, the first one holding only 3 bits.
+	var buf [26]byte
+	hi := binary.BigEndian.Uint64(u[0:8])
+	lo := binary.BigEndian.Uint64(u[8:16])
+	for i := 25; i >= 0; i-- {
+		buf[i] = crockfordBase32[lo&0x1f]
+		lo = (lo >> 5) | (hi << 59)
+		hi >>= 5
+	}
+	return string(buf[:])
+}
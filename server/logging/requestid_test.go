@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/jamillosantos/logctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestNewUUID(t *testing.T) {
+	id := NewUUID()
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), id)
+	assert.NotEqual(t, id, NewUUID())
+}
+
+func TestNewULID(t *testing.T) {
+	id := NewULID()
+	assert.Regexp(t, regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`), id)
+	assert.NotEqual(t, id, NewULID())
+}
+
+func TestInterceptor_RequestID(t *testing.T) {
+	t.Run("should propagate the incoming request ID", func(t *testing.T) {
+		sts := &fakeServerTransportStream{}
+		ctx, obs := createObserver()
+		ctx = grpc.NewContextWithServerTransportStream(ctx, sts)
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-request-id", "req-123"))
+
+		var gotID string
+		_, _ = UnaryInterceptor(WithRequestID())(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			gotID, _ = RequestIDFromContext(ctx)
+			logctx.Info(ctx, "handler log")
+			return nil, nil
+		})
+		assert.Equal(t, "req-123", gotID)
+		assert.Equal(t, []string{"req-123"}, sts.header.Get("x-request-id"))
+
+		entries := obs.All()
+		require.Len(t, entries, 2)
+		for _, entry := range entries {
+			assert.Equal(t, "req-123", entry.ContextMap()[fieldGRPCRequestID])
+		}
+	})
+
+	t.Run("should generate a request ID when it is missing or invalid", func(t *testing.T) {
+		for _, md := range []metadata.MD{nil, metadata.Pairs("x-correlation-id", strings.Repeat("a", maxRequestIDLength+1))} {
+			sts := &fakeServerTransportStream{}
+			ctx, _ := createObserver()
+			ctx = grpc.NewContextWithServerTransportStream(ctx, sts)
+			ctx = metadata.NewIncomingContext(ctx, md)
+
+			var gotID string
+			_, _ = UnaryInterceptor(WithRequestID(
+				RequestIDKey("x-correlation-id"),
+				RequestIDGenerator(func() string { return "generated" }),
+				RequestIDEcho(false),
+			))(ctx, nil, &grpc.UnaryServerInfo{
+				FullMethod: "/pkg.Service/Method",
+			}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				gotID, _ = RequestIDFromContext(ctx)
+				return nil, nil
+			})
+			assert.Equal(t, "generated", gotID)
+			assert.Empty(t, sts.header)
+		}
+	})
+
+	t.Run("should not have a request ID when disabled", func(t *testing.T) {
+		ctx, _ := createObserver()
+		_, _ = UnaryInterceptor()(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			_, ok := RequestIDFromContext(ctx)
+			assert.False(t, ok)
+			return nil, nil
+		})
+	})
+}
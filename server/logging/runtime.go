@@ -0,0 +1,126 @@
+package logging
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jamillosantos/logctx"
+	"go.uber.org/zap/zapcore"
+)
+
+// MethodConfig is the runtime configuration of a method. Nil fields keep the behavior set by the interceptor options.
+type MethodConfig struct {
+	// Level is the level of the logctx logger of the calls, including the entries written by the interceptor. It can be
+	// lower than the level of the logger (eg: Debug to troubleshoot a method).
+	Level *zapcore.Level
+	// Payloads enables, or disables, logging the request and the response. When enabled and no extractor is
+	// configured, the proto extractors are used, redacting the payloads with the redactor set by WithRedactor or, when
+	// there is none, the fields annotated with `debug_redact`.
+	Payloads *bool
+	// SampleRate is the fraction, between 0 and 1, of the successful calls whose completion is logged. Failed calls
+	// are always logged.
+	SampleRate *float64
+}
+
+// RuntimeConfig holds the configuration of the methods that can be changed while the server is running, either
+// directly or through the admin service (see RegisterAdminService). The interceptors read it once per call, without
+// locking. The zero value is an empty configuration ready to use.
+type RuntimeConfig struct {
+	mu      sync.Mutex
+	methods atomic.Pointer[map[string]MethodConfig]
+}
+
+// NewRuntimeConfig returns an empty RuntimeConfig, to be used with WithRuntimeConfig.
+func NewRuntimeConfig() *RuntimeConfig {
+	return &RuntimeConfig{}
+}
+
+// load returns the current configuration, which is nil until a method is configured.
+func (c *RuntimeConfig) load() map[string]MethodConfig {
+	if methods := c.methods.Load(); methods != nil {
+		return *methods
+	}
+	return nil
+}
+
+// Method returns the configuration of fullMethod (eg: "/package.Service/Method").
+func (c *RuntimeConfig) Method(fullMethod string) MethodConfig {
+	return c.load()[fullMethod]
+}
+
+// Methods returns the full methods that have a configuration, sorted.
+func (c *RuntimeConfig) Methods() []string {
+	methods := c.load()
+	names := make([]string, 0, len(methods))
+	for name := range methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetMethod replaces the configuration of fullMethod.
+func (c *RuntimeConfig) SetMethod(fullMethod string, config MethodConfig) {
+	c.update(func(methods map[string]MethodConfig) {
+		methods[fullMethod] = config
+	})
+}
+
+// ResetMethod removes the configuration of fullMethod, restoring the behavior set by the interceptor options.
+func (c *RuntimeConfig) ResetMethod(fullMethod string) {
+	c.update(func(methods map[string]MethodConfig) {
+		delete(methods, fullMethod)
+	})
+}
+
+// update applies fn to a copy of the configuration and stores it, so the calls in flight keep reading the previous
+// one.
+func (c *RuntimeConfig) update(fn func(methods map[string]MethodConfig)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	current := c.load()
+	methods := make(map[string]MethodConfig, len(current)+1)
+	for k, v := range current {
+		methods[k] = v
+	}
+	fn(methods)
+	c.methods.Store(&methods)
+}
+
+// withRuntimeConfig applies the runtime configuration of fullMethod to the call. It returns the options to be used by
+// the call.
+func withRuntimeConfig(ctx context.Context, fullMethod string, opts loggingOptions) (context.Context, loggingOptions) {
+	if opts.runtimeConfig == nil {
+		return ctx, opts
+	}
+	config := opts.runtimeConfig.Method(fullMethod)
+	if config.Level != nil {
+		ctx = logctx.WithLogger(ctx, levelLogger(logctx.From(ctx), *config.Level))
+	}
+	if config.Payloads != nil {
+		if !*config.Payloads {
+			opts.extractRequest = nil
+			opts.extractResponse = nil
+		} else {
+			redactor := ProtoRedactor(payloadRedactor(opts))
+			if opts.extractRequest == nil {
+				opts.extractRequest = ProtoRequestExtractor(redactor)
+			}
+			if opts.extractResponse == nil {
+				opts.extractResponse = ProtoResponseExtractor(redactor)
+			}
+		}
+	}
+	if config.SampleRate != nil && rand.Float64() >= *config.SampleRate {
+		// The decider is added last, so the decisions of the other deciders (eg: DecisionSkip) take precedence.
+		opts.deciders = append(opts.deciders[:len(opts.deciders):len(opts.deciders)], sampledOutDecider)
+	}
+	return ctx, opts
+}
+
+func sampledOutDecider(context.Context, string, error) Decision {
+	return DecisionLogOnError
+}
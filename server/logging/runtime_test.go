@@ -0,0 +1,129 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jamillosantos/logctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestRuntimeConfig(t *testing.T) {
+	config := NewRuntimeConfig()
+	assert.Empty(t, config.Methods())
+
+	level := zapcore.DebugLevel
+	config.SetMethod("/pkg.Service/B", MethodConfig{Level: &level})
+	config.SetMethod("/pkg.Service/A", MethodConfig{})
+	assert.Equal(t, []string{"/pkg.Service/A", "/pkg.Service/B"}, config.Methods())
+	assert.Equal(t, &level, config.Method("/pkg.Service/B").Level)
+
+	config.ResetMethod("/pkg.Service/B")
+	assert.Equal(t, []string{"/pkg.Service/A"}, config.Methods())
+	assert.Nil(t, config.Method("/pkg.Service/B").Level)
+}
+
+func TestRuntimeConfig_ZeroValue(t *testing.T) {
+	config := &RuntimeConfig{}
+	assert.Empty(t, config.Methods())
+	assert.Nil(t, config.Method("/pkg.Service/A").Level)
+
+	config.ResetMethod("/pkg.Service/A")
+	assert.Empty(t, config.Methods())
+
+	config.SetMethod("/pkg.Service/A", MethodConfig{})
+	assert.Equal(t, []string{"/pkg.Service/A"}, config.Methods())
+}
+
+func TestWithRuntimeConfig(t *testing.T) {
+	config := NewRuntimeConfig()
+	interceptor := UnaryInterceptor(WithRuntimeConfig(config))
+	call := func(err error) *observer.ObservedLogs {
+		return callMethod(context.Background(), zapcore.InfoLevel, interceptor, wrapperspb.String("request"), func(ctx context.Context, req interface{}) (interface{}, error) {
+			logctx.From(ctx).Debug("debug entry")
+			return wrapperspb.String("response"), err
+		})
+	}
+
+	t.Run("should use the options when the method has no configuration", func(t *testing.T) {
+		obs := call(nil)
+
+		assert.Zero(t, obs.FilterMessage("debug entry").Len())
+		completed := obs.FilterMessage("Method completed").All()
+		require.Len(t, completed, 1)
+		assert.NotContains(t, completed[0].ContextMap(), fieldGRPCRequest)
+	})
+
+	t.Run("should apply the level", func(t *testing.T) {
+		level := zapcore.DebugLevel
+		config.SetMethod("/pkg.Service/Method", MethodConfig{Level: &level})
+		defer config.ResetMethod("/pkg.Service/Method")
+
+		assert.Equal(t, 1, call(nil).FilterMessage("debug entry").Len())
+
+		level = zapcore.ErrorLevel
+		config.SetMethod("/pkg.Service/Method", MethodConfig{Level: &level})
+		assert.Zero(t, call(nil).Len(), "the completion should be below the level")
+	})
+
+	t.Run("should apply the payload logging", func(t *testing.T) {
+		payloads := true
+		config.SetMethod("/pkg.Service/Method", MethodConfig{Payloads: &payloads})
+		defer config.ResetMethod("/pkg.Service/Method")
+
+		fields := call(nil).FilterMessage("Method completed").All()[0].ContextMap()
+		assert.Equal(t, map[string]interface{}{"value": "request"}, fields[fieldGRPCRequest])
+		assert.Equal(t, map[string]interface{}{"value": "response"}, fields[fieldGRPCResponse])
+	})
+
+	t.Run("should redact the payloads", func(t *testing.T) {
+		md, _ := createRedactTestTypes(t)
+		payloads := true
+		config.SetMethod("/pkg.Service/Method", MethodConfig{Payloads: &payloads})
+		defer config.ResetMethod("/pkg.Service/Method")
+
+		obs := callMethod(context.Background(), zapcore.InfoLevel, interceptor, newRedactTestUser(md), func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+
+		request := obs.FilterMessage("Method completed").All()[0].ContextMap()[fieldGRPCRequest].(map[string]interface{})
+		assert.Equal(t, "john", request["name"])
+		assert.Equal(t, redactedValue, request["password"])
+	})
+
+	t.Run("should apply the sampling to the successful calls", func(t *testing.T) {
+		rate := 0.0
+		config.SetMethod("/pkg.Service/Method", MethodConfig{SampleRate: &rate})
+		defer config.ResetMethod("/pkg.Service/Method")
+
+		assert.Zero(t, call(nil).Len())
+		assert.Equal(t, 1, call(status.Error(codes.Internal, "failed")).FilterMessage("Method completed with error").Len())
+
+		rate = 1
+		config.SetMethod("/pkg.Service/Method", MethodConfig{SampleRate: &rate})
+		assert.Equal(t, 1, call(nil).FilterMessage("Method completed").Len())
+	})
+}
+
+func TestWithRuntimeConfig_Stream(t *testing.T) {
+	config := NewRuntimeConfig()
+	rate := 0.0
+	config.SetMethod("/pkg.Service/Method", MethodConfig{SampleRate: &rate})
+
+	ctx, obs := createObserver()
+	err := StreamInterceptor(WithRuntimeConfig(config), WithOperationStarted(true))(nil, &fakeServerStream{ctx: ctx, recv: 1}, &grpc.StreamServerInfo{
+		FullMethod: "/pkg.Service/Method",
+	}, func(srv interface{}, stream grpc.ServerStream) error {
+		require.NoError(t, stream.RecvMsg(nil))
+		return stream.SendMsg(nil)
+	})
+	require.NoError(t, err)
+	assert.Zero(t, obs.Len(), "the started and completed entries of sampled out calls should not be logged")
+}
@@ -0,0 +1,18 @@
+//go:build go1.21
+
+package logging
+
+import (
+	"log/slog"
+
+	"go.uber.org/zap"
+
+	"github.com/jamillosantos/go-grpc-interceptors/internal/slogcore"
+)
+
+// WithSlog writes the entries of the interceptor, and the ones written by the handler through logctx, to a log/slog
+// logger. The fields are converted into slog attributes with the same keys, and objects (such as the request, the
+// response and the error details) into groups.
+func WithSlog(logger *slog.Logger) Option {
+	return WithLogger(zap.New(slogcore.New(logger.Handler())))
+}
@@ -0,0 +1,57 @@
+//go:build go1.21
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/jamillosantos/logctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWithSlog(t *testing.T) {
+	st, err := status.New(codes.InvalidArgument, "invalid").WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "name", Description: "required"}},
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	_, _ = UnaryInterceptor(WithSlog(logger), WithProtoPayloads())(context.Background(), &errdetails.RequestInfo{RequestId: "123"}, &grpc.UnaryServerInfo{
+		FullMethod: "/pkg.Service/Method",
+	}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		logctx.Info(ctx, "handler log")
+		return nil, st.Err()
+	})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var handlerRecord, record map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &handlerRecord))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+
+	assert.Equal(t, "handler log", handlerRecord["msg"])
+	assert.Equal(t, "Method", handlerRecord[fieldGRPCMethod])
+
+	assert.Equal(t, "Method completed with error", record["msg"])
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "InvalidArgument", record[fieldGRPCStatus])
+	assert.Equal(t, map[string]interface{}{"request_id": "123"}, record[fieldGRPCRequest])
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"$type": "BadRequest",
+		"field_violations": []interface{}{
+			map[string]interface{}{"field": "name", "description": "required"},
+		},
+	}}, record[fieldGRPCErrorDetails])
+}
@@ -0,0 +1,44 @@
+package logging
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	fieldGRPCSlow          = "grpc.slow"
+	fieldGRPCSlowThreshold = "grpc.slow_threshold"
+)
+
+// slowOptions holds the latency thresholds above which a call is slow. Per method thresholds take precedence over the
+// global one, and a zero threshold disables the detection.
+type slowOptions struct {
+	threshold time.Duration
+	methods   map[string]time.Duration
+	level     zapcore.Level
+}
+
+func defaultSlowOptions() slowOptions {
+	return slowOptions{
+		level: zapcore.WarnLevel,
+	}
+}
+
+// exceeded returns the threshold of fullMethod and whether duration reached it.
+func (s slowOptions) exceeded(fullMethod string, duration time.Duration) (time.Duration, bool) {
+	threshold, ok := s.methods[fullMethod]
+	if !ok {
+		threshold = s.threshold
+	}
+	return threshold, threshold > 0 && duration >= threshold
+}
+
+// fields returns the fields added to the completion entry of a slow call.
+func (s slowOptions) fields(threshold time.Duration, opts loggingOptions) []zap.Field {
+	return []zap.Field{
+		zap.Bool(fieldGRPCSlow, true),
+		opts.durationField(fieldGRPCSlowThreshold, threshold),
+	}
+}
@@ -0,0 +1,91 @@
+package logging
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSlowOptions_exceeded(t *testing.T) {
+	var opts loggingOptions
+	opts.slow = defaultSlowOptions()
+	WithSlowThreshold(100 * time.Millisecond)(&opts)
+	WithMethodSlowThreshold("/pkg.Service/Fast", 10*time.Millisecond)(&opts)
+	WithMethodSlowThreshold("/pkg.Service/Stream", 0)(&opts)
+
+	threshold, slow := opts.slow.exceeded("/pkg.Service/Method", 100*time.Millisecond)
+	assert.True(t, slow)
+	assert.Equal(t, 100*time.Millisecond, threshold)
+
+	_, slow = opts.slow.exceeded("/pkg.Service/Method", 99*time.Millisecond)
+	assert.False(t, slow)
+
+	threshold, slow = opts.slow.exceeded("/pkg.Service/Fast", 20*time.Millisecond)
+	assert.True(t, slow)
+	assert.Equal(t, 10*time.Millisecond, threshold)
+
+	_, slow = opts.slow.exceeded("/pkg.Service/Stream", time.Hour)
+	assert.False(t, slow, "a zero threshold should disable the detection")
+
+	_, slow = defaultSlowOptions().exceeded("/pkg.Service/Method", time.Hour)
+	assert.False(t, slow, "the detection should be disabled by default")
+}
+
+func TestWithSlowThreshold(t *testing.T) {
+	call := func(interceptor grpc.UnaryServerInterceptor, delay time.Duration, err error) *observer.ObservedLogs {
+		return callMethod(context.Background(), zapcore.DebugLevel, interceptor, nil, func(ctx context.Context, req interface{}) (interface{}, error) {
+			time.Sleep(delay)
+			return nil, err
+		})
+	}
+
+	t.Run("should log slow calls when the completion logging is disabled", func(t *testing.T) {
+		obs := call(UnaryInterceptor(WithOperationCompleted(false), WithSlowThreshold(10*time.Millisecond)), 20*time.Millisecond, nil)
+
+		require.Equal(t, 1, obs.Len())
+		entry := obs.All()[0]
+		assert.Equal(t, "Method completed", entry.Message)
+		assert.Equal(t, zapcore.WarnLevel, entry.Level)
+		fields := entry.ContextMap()
+		assert.Equal(t, true, fields[fieldGRPCSlow])
+		assert.Equal(t, 10*time.Millisecond, fields[fieldGRPCSlowThreshold])
+	})
+
+	t.Run("should not log fast calls when the completion logging is disabled", func(t *testing.T) {
+		entries := call(UnaryInterceptor(WithOperationCompleted(false), WithSlowThreshold(time.Second)), 0, nil).All()
+
+		assert.Empty(t, entries)
+	})
+
+	t.Run("should keep the level of the status code when it is higher", func(t *testing.T) {
+		entries := call(UnaryInterceptor(WithSlowThreshold(time.Nanosecond)), time.Millisecond, status.Error(codes.Internal, "failed")).All()
+
+		require.Len(t, entries, 1)
+		assert.Equal(t, zapcore.ErrorLevel, entries[0].Level)
+	})
+
+	t.Run("should use the level set by WithSlowLevel", func(t *testing.T) {
+		entries := call(UnaryInterceptor(WithSlowThreshold(time.Nanosecond), WithSlowLevel(zapcore.ErrorLevel)), time.Millisecond, nil).All()
+
+		require.Len(t, entries, 1)
+		assert.Equal(t, zapcore.ErrorLevel, entries[0].Level)
+	})
+
+	t.Run("should use the method threshold", func(t *testing.T) {
+		entries := call(UnaryInterceptor(
+			WithOperationCompleted(false),
+			WithSlowThreshold(time.Nanosecond),
+			WithMethodSlowThreshold("/pkg.Service/Method", time.Second),
+		), time.Millisecond, nil).All()
+
+		assert.Empty(t, entries)
+	})
+}
@@ -0,0 +1,148 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jamillosantos/logctx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// StreamInterceptor is the streaming counterpart of UnaryInterceptor. It accepts the same options and logs the start
+// and the completion of the stream, including the final status and the number of messages sent and received.
+//
+// When a request (or response) extractor is configured, it is applied to every message received (or sent) through
+// the stream and each message is logged individually.
+func StreamInterceptor(options ...Option) grpc.StreamServerInterceptor {
+	opts := newOptions(options)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx := ss.Context()
+		if opts.logger != nil {
+			ctx = logctx.WithLogger(ctx, opts.logger)
+		}
+		ctx, opts := withRuntimeConfig(ctx, info.FullMethod, opts)
+		ctx, opts = withDebugLog(ctx, info.FullMethod, opts)
+		call := newCallInfo(ctx, info.FullMethod, start, opts)
+		if opts.debugBuffer != nil {
+			call.debugBuffer = newDebugBuffer(opts.debugBuffer.size)
+			ctx = logctx.WithLogger(ctx, bufferingLogger(logctx.From(ctx), call.debugBuffer))
+		}
+		if call.outgoing != nil {
+			ctx = captureOutgoingMetadata(ctx, call.outgoing)
+		}
+		if opts.requestID != nil {
+			ctx = withRequestID(ctx, opts.requestID, opts.naming)
+		}
+		if opts.traceContext {
+			ctx = withTraceContext(ctx, opts.naming)
+		}
+		ctx = context.WithValue(ctx, ctxKeyAddFields, call.fields)
+		ctx = logRequest(ctx, call, nil, opts)
+
+		stream := &loggingServerStream{
+			ServerStream: ss,
+			ctx:          ctx,
+			call:         call,
+			opts:         opts,
+		}
+		err := handler(srv, stream)
+		completeDebugBuffer(call, err, opts.debugBuffer)
+
+		logResponse(ctx, call, nil, nil, err, opts,
+			zap.Uint64(fieldGRPCMsgsReceived, stream.received.Load()),
+			zap.Uint64(fieldGRPCMsgsSent, stream.sent.Load()),
+		)
+		return err
+	}
+}
+
+// loggingServerStream wraps a grpc.ServerStream counting the messages that go through it and applying the configured
+// extractors to each one of them.
+type loggingServerStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	call     *callInfo
+	opts     loggingOptions
+	received atomic.Uint64
+	sent     atomic.Uint64
+}
+
+func (s *loggingServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *loggingServerStream) SetHeader(md metadata.MD) error {
+	err := s.ServerStream.SetHeader(md)
+	if err == nil && s.call.outgoing != nil {
+		s.call.outgoing.addHeader(md)
+	}
+	return err
+}
+
+func (s *loggingServerStream) SendHeader(md metadata.MD) error {
+	err := s.ServerStream.SendHeader(md)
+	if err == nil && s.call.outgoing != nil {
+		s.call.outgoing.addHeader(md)
+	}
+	return err
+}
+
+func (s *loggingServerStream) SetTrailer(md metadata.MD) {
+	s.ServerStream.SetTrailer(md)
+	if s.call.outgoing != nil {
+		s.call.outgoing.addTrailer(md)
+	}
+}
+
+func (s *loggingServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err != nil {
+		return err
+	}
+	s.received.Add(1)
+
+	if s.opts.extractRequest == nil {
+		return nil
+	}
+	_, reqObj, err := s.opts.extractRequest(s.ctx, m)
+	if err != nil {
+		return err
+	}
+	s.logMessage(messageStreamRecv, fieldGRPCRequest, reqObj)
+	return nil
+}
+
+func (s *loggingServerStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err != nil {
+		return err
+	}
+	s.sent.Add(1)
+
+	if s.opts.extractResponse == nil {
+		return nil
+	}
+	s.logMessage(messageStreamSend, fieldGRPCResponse, s.opts.extractResponse(s.ctx, m))
+	return nil
+}
+
+func (s *loggingServerStream) logMessage(message, key string, obj zapcore.ObjectMarshaler) {
+	level, ok := s.call.startLevel()
+	if !ok {
+		return
+	}
+	// RecvMsg and SendMsg can be called from different goroutines, so the common fields are copied instead of appended.
+	entryFields := s.call.entryFields(s.opts)
+	fields := make([]zap.Field, len(entryFields), len(entryFields)+1)
+	copy(fields, entryFields)
+	if obj != nil {
+		fields = append(fields, zap.Object(key, obj))
+	}
+	writeLog(s.ctx, level, fmt.Sprintf(message, s.call.method), s.opts.naming.rename(fields)...)
+}
@@ -0,0 +1,121 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	recv int
+}
+
+func (f *fakeServerStream) Context() context.Context {
+	return f.ctx
+}
+
+func (f *fakeServerStream) RecvMsg(_ interface{}) error {
+	if f.recv == 0 {
+		return io.EOF
+	}
+	f.recv--
+	return nil
+}
+
+func (f *fakeServerStream) SendMsg(_ interface{}) error {
+	return nil
+}
+
+func TestStreamInterceptor(t *testing.T) {
+	t.Run("should log the stream start and completion with message counters", func(t *testing.T) {
+		ctx, obs := createObserver()
+		err := StreamInterceptor(
+			WithOperationStarted(true),
+			WithOperationCompleted(true),
+		)(nil, &fakeServerStream{ctx: ctx, recv: 2}, &grpc.StreamServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(srv interface{}, stream grpc.ServerStream) error {
+			for stream.RecvMsg(nil) == nil {
+			}
+			return stream.SendMsg(nil)
+		})
+		require.NoError(t, err)
+		entries := obs.All()
+		require.Len(t, entries, 2)
+		assert.Equal(t, "Method started", entries[0].Message)
+		assert.Equal(t, "Method completed", entries[1].Message)
+		fields := entries[1].ContextMap()
+		assert.Equal(t, "OK", fields[fieldGRPCStatus])
+		assert.Equal(t, uint64(2), fields[fieldGRPCMsgsReceived])
+		assert.Equal(t, uint64(1), fields[fieldGRPCMsgsSent])
+	})
+
+	t.Run("should log each message when extractors are configured", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		ctx, obs := createObserver()
+		err := StreamInterceptor(
+			WithRequestExtractor(func(ctx context.Context, req interface{}) (context.Context, zapcore.ObjectMarshaler, error) {
+				return ctx, createMockObjectMarshaler(ctrl), nil
+			}),
+			WithResponseExtractor(func(ctx context.Context, resp interface{}) zapcore.ObjectMarshaler {
+				return createMockObjectMarshaler(ctrl)
+			}),
+		)(nil, &fakeServerStream{ctx: ctx, recv: 1}, &grpc.StreamServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(srv interface{}, stream grpc.ServerStream) error {
+			require.NoError(t, stream.RecvMsg(nil))
+			return stream.SendMsg(nil)
+		})
+		require.NoError(t, err)
+		entries := obs.All()
+		require.Len(t, entries, 3)
+		assert.Equal(t, "Method message received", entries[0].Message)
+		assert.Equal(t, fieldGRPCRequest, entries[0].Context[3].Key)
+		assert.Equal(t, "Method message sent", entries[1].Message)
+		assert.Equal(t, fieldGRPCResponse, entries[1].Context[3].Key)
+		assert.Equal(t, "Method completed", entries[2].Message)
+	})
+
+	t.Run("should fail the receive when the request extractor fails", func(t *testing.T) {
+		wantErr := errors.New("extractor error")
+		ctx, _ := createObserver()
+		err := StreamInterceptor(
+			WithRequestExtractor(func(ctx context.Context, req interface{}) (context.Context, zapcore.ObjectMarshaler, error) {
+				return nil, nil, wantErr
+			}),
+		)(nil, &fakeServerStream{ctx: ctx, recv: 1}, &grpc.StreamServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(srv interface{}, stream grpc.ServerStream) error {
+			return stream.RecvMsg(nil)
+		})
+		assert.ErrorIs(t, err, wantErr)
+	})
+
+	t.Run("should log completed with error when handler fails", func(t *testing.T) {
+		ctx, obs := createObserver()
+		err := StreamInterceptor(
+			WithOperationCompleted(false),
+		)(nil, &fakeServerStream{ctx: ctx}, &grpc.StreamServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(srv interface{}, stream grpc.ServerStream) error {
+			return status.Error(codes.Unavailable, "unavailable")
+		})
+		require.Error(t, err)
+		entries := obs.All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, "Method completed with error", entries[0].Message)
+		assert.Equal(t, zapcore.WarnLevel, entries[0].Level)
+		assert.Equal(t, "Unavailable", entries[0].ContextMap()[fieldGRPCStatus])
+	})
+}
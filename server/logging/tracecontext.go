@@ -0,0 +1,24 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/jamillosantos/logctx"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/jamillosantos/go-grpc-interceptors/internal/tracecontext"
+)
+
+// withTraceContext starts a new span for the call, as a child of the trace context found in the incoming metadata,
+// and adds it to the context and to its logctx logger.
+func withTraceContext(ctx context.Context, naming NamingScheme) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	tc, ok := tracecontext.FromMetadata(md)
+	if ok {
+		tc = tc.Child()
+	} else {
+		tc = tracecontext.New()
+	}
+	ctx = tracecontext.NewContext(ctx, tc)
+	return logctx.WithFields(ctx, naming.rename(tc.Fields())...)
+}
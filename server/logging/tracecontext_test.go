@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jamillosantos/logctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/jamillosantos/go-grpc-interceptors/internal/tracecontext"
+)
+
+func TestInterceptor_TraceContext(t *testing.T) {
+	t.Run("should continue the incoming trace", func(t *testing.T) {
+		ctx, obs := createObserver()
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(
+			tracecontext.HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			tracecontext.HeaderTracestate, "congo=t61rcWkgMzE",
+		))
+
+		var tc tracecontext.TraceContext
+		_, _ = UnaryInterceptor(WithTraceContext(true))(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			tc, _ = tracecontext.FromContext(ctx)
+			logctx.Info(ctx, "handler log")
+			return nil, nil
+		})
+		assert.Equal(t, "congo=t61rcWkgMzE", tc.State)
+
+		entries := obs.All()
+		require.Len(t, entries, 2)
+		for _, entry := range entries {
+			fields := entry.ContextMap()
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", fields[tracecontext.FieldTraceID])
+			assert.Equal(t, "00f067aa0ba902b7", fields[tracecontext.FieldParentSpanID])
+			assert.Equal(t, tc.Traceparent()[36:52], fields[tracecontext.FieldSpanID])
+		}
+	})
+
+	t.Run("should start a new trace when the traceparent is malformed", func(t *testing.T) {
+		ctx, obs := createObserver()
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(tracecontext.HeaderTraceparent, "invalid"))
+
+		var ok bool
+		_, _ = UnaryInterceptor(WithTraceContext(true))(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			_, ok = tracecontext.FromContext(ctx)
+			return nil, nil
+		})
+		assert.True(t, ok)
+
+		fields := obs.All()[0].ContextMap()
+		assert.Contains(t, fields, tracecontext.FieldTraceID)
+		assert.Contains(t, fields, tracecontext.FieldSpanID)
+		assert.NotContains(t, fields, tracecontext.FieldParentSpanID)
+	})
+}
@@ -0,0 +1,137 @@
+// Package recovery provides interceptors that recover the panics of the handlers, converting them into errors with
+// the codes.Internal status, instead of crashing the process.
+//
+// The interceptors should be the innermost ones (the last in the chain), so the other interceptors, such as the
+// logging ones, see the error returned in place of the panic.
+package recovery
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+
+	"github.com/jamillosantos/logctx"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	fieldGRPCFullMethod = "grpc.full_method"
+	fieldPanicValue     = "grpc.panic.value"
+	fieldPanicStack     = "grpc.panic.stack"
+
+	messagePanic = "panic recovered"
+)
+
+// Handler converts a recovered panic into the error returned to the client. stack is the stack trace of the
+// goroutine that panicked.
+type Handler func(ctx context.Context, fullMethod string, p interface{}, stack []byte) error
+
+type opts struct {
+	handler   Handler
+	debugInfo bool
+	metric    func(ctx context.Context, fullMethod string, p interface{})
+}
+
+// Option is a function that configures the recovery interceptors.
+type Option func(*opts)
+
+func defaultOptions() opts {
+	return opts{
+		handler: defaultHandler,
+	}
+}
+
+// UnaryInterceptor recovers the panics of unary handlers. See the package documentation.
+func UnaryInterceptor(options ...Option) grpc.UnaryServerInterceptor {
+	o := defaultOptions()
+	for _, opt := range options {
+		opt(&o)
+	}
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				resp, err = nil, o.recovered(ctx, info.FullMethod, p, debug.Stack())
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor recovers the panics of stream handlers. See the package documentation.
+func StreamInterceptor(options ...Option) grpc.StreamServerInterceptor {
+	o := defaultOptions()
+	for _, opt := range options {
+		opt(&o)
+	}
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = o.recovered(ss.Context(), info.FullMethod, p, debug.Stack())
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// recovered logs the panic p, reports it to the metric callback and returns the error to be sent to the client.
+func (o *opts) recovered(ctx context.Context, fullMethod string, p interface{}, stack []byte) error {
+	logctx.Error(ctx, messagePanic,
+		zap.String(fieldGRPCFullMethod, fullMethod),
+		zap.Any(fieldPanicValue, p),
+		zap.ByteString(fieldPanicStack, stack),
+	)
+	if o.metric != nil {
+		o.metric(ctx, fullMethod, p)
+	}
+
+	err := o.handler(ctx, fullMethod, p, stack)
+	if err == nil {
+		// The panic must never turn into a successful call.
+		err = defaultHandler(ctx, fullMethod, p, stack)
+	}
+	if !o.debugInfo {
+		return err
+	}
+	st, detailsErr := status.Convert(err).WithDetails(&errdetails.DebugInfo{
+		StackEntries: strings.Split(strings.TrimSpace(string(stack)), "\n"),
+		Detail:       fmt.Sprint(p),
+	})
+	if detailsErr != nil {
+		return err
+	}
+	return st.Err()
+}
+
+func defaultHandler(_ context.Context, _ string, _ interface{}, _ []byte) error {
+	return status.Error(codes.Internal, "internal error")
+}
+
+// WithHandler sets the function that converts the recovered panics into the errors returned to the client. By
+// default, an error with the codes.Internal status and no information about the panic is returned, which is also the
+// case when handler returns nil.
+func WithHandler(handler Handler) Option {
+	return func(o *opts) {
+		o.handler = handler
+	}
+}
+
+// WithDebugInfo enables, or disables, adding an errdetails.DebugInfo, with the panic value and the stack trace, to
+// the status returned to the client. As it exposes the internals of the server, it should only be enabled for
+// internal services or in development.
+func WithDebugInfo(enable bool) Option {
+	return func(o *opts) {
+		o.debugInfo = enable
+	}
+}
+
+// WithMetric sets a callback called for every recovered panic (eg: to increment a counter).
+func WithMetric(metric func(ctx context.Context, fullMethod string, p interface{})) Option {
+	return func(o *opts) {
+		o.metric = metric
+	}
+}
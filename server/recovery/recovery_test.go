@@ -0,0 +1,121 @@
+package recovery
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jamillosantos/logctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (f *fakeServerStream) Context() context.Context {
+	return f.ctx
+}
+
+func createObserver() (context.Context, *observer.ObservedLogs) {
+	zc, obs := observer.New(zapcore.DebugLevel)
+	return logctx.WithLogger(context.Background(), zap.New(zc)), obs
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	t.Run("should convert the panic into an internal error and log it", func(t *testing.T) {
+		ctx, obs := createObserver()
+		var metricMethod string
+		resp, err := UnaryInterceptor(WithMetric(func(ctx context.Context, fullMethod string, p interface{}) {
+			metricMethod = fullMethod
+		}))(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			panic("boom")
+		})
+		assert.Nil(t, resp)
+		assert.Equal(t, codes.Internal, status.Code(err))
+		assert.Empty(t, status.Convert(err).Details())
+		assert.Equal(t, "/pkg.Service/Method", metricMethod)
+
+		entries := obs.All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, zapcore.ErrorLevel, entries[0].Level)
+		fields := entries[0].ContextMap()
+		assert.Equal(t, "boom", fields[fieldPanicValue])
+		assert.Contains(t, fields[fieldPanicStack], "recovery_test.go")
+		assert.Equal(t, "/pkg.Service/Method", fields[fieldGRPCFullMethod])
+	})
+
+	t.Run("should add the debug info", func(t *testing.T) {
+		ctx, _ := createObserver()
+		_, err := UnaryInterceptor(WithDebugInfo(true))(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			panic(errors.New("boom"))
+		})
+		details := status.Convert(err).Details()
+		require.Len(t, details, 1)
+		debugInfo, ok := details[0].(*errdetails.DebugInfo)
+		require.True(t, ok)
+		assert.Equal(t, "boom", debugInfo.Detail)
+		assert.NotEmpty(t, debugInfo.StackEntries)
+	})
+
+	t.Run("should use the custom handler", func(t *testing.T) {
+		ctx, _ := createObserver()
+		_, err := UnaryInterceptor(WithHandler(func(ctx context.Context, fullMethod string, p interface{}, stack []byte) error {
+			return status.Errorf(codes.Unavailable, "%v", p)
+		}))(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			panic("boom")
+		})
+		assert.Equal(t, status.Error(codes.Unavailable, "boom").Error(), err.Error())
+	})
+
+	t.Run("should fall back to the default handler when the custom one returns nil", func(t *testing.T) {
+		ctx, _ := createObserver()
+		_, err := UnaryInterceptor(WithDebugInfo(true), WithHandler(func(ctx context.Context, fullMethod string, p interface{}, stack []byte) error {
+			return nil
+		}))(ctx, nil, &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			panic("boom")
+		})
+		assert.Equal(t, codes.Internal, status.Code(err))
+		assert.Len(t, status.Convert(err).Details(), 1, "the debug info should be added")
+	})
+
+	t.Run("should not interfere when there is no panic", func(t *testing.T) {
+		ctx, obs := createObserver()
+		resp, err := UnaryInterceptor()(ctx, "req", &grpc.UnaryServerInfo{
+			FullMethod: "/pkg.Service/Method",
+		}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "resp", nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "resp", resp)
+		assert.Empty(t, obs.All())
+	})
+}
+
+func TestStreamInterceptor(t *testing.T) {
+	ctx, obs := createObserver()
+	err := StreamInterceptor()(nil, &fakeServerStream{ctx: ctx}, &grpc.StreamServerInfo{
+		FullMethod: "/pkg.Service/Method",
+	}, func(srv interface{}, stream grpc.ServerStream) error {
+		panic("boom")
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	require.Len(t, obs.All(), 1)
+}